@@ -18,18 +18,30 @@ type RandomConfig struct {
 
 type WalletConfig struct {
 	PrivateKey      string `json:"private_key"`
+	Address         string `json:"address"` // wallet in the keystore, instead of private_key
+	Label           string `json:"label"`   // or its label in the vault file
 	Endpoint        string `json:"endpoint"`
 	RevertAllowance bool   `json:"revert_allowance"`
 	BaseName        string `json:"base_name"`
 	NameUsed        bool
-	UsedRange       int64  `json:"used_range"`
-	PoolUsedRange   int64  `json:"used_range_in_pools"`
-	Bridge          string `json:"bridge"`
-	Token           string `json:"token"`
-	ActionNumMIN    *int   `json:"action_num_min"`
-	ActionNumMAX    *int   `json:"action_num_max"`
-	ActionTimeMIN   *int   `json:"action_time_window_MIN"`
-	ActionTimeMAX   *int   `json:"action_time_window_MAX"`
+	UsedRange       int64   `json:"used_range"`
+	PoolUsedRange   int64   `json:"used_range_in_pools"`
+	Bridge          string  `json:"bridge"`
+	Token           string  `json:"token"`
+	ActionNumMIN    *int    `json:"action_num_min"`
+	ActionNumMAX    *int    `json:"action_num_max"`
+	ActionTimeMIN   *int    `json:"action_time_window_MIN"`
+	ActionTimeMAX   *int    `json:"action_time_window_MAX"`
+	MaxGasPriceGwei float64 `json:"max_gas_price_gwei"`
+
+	Proxy   string   `json:"proxy"`   // http(s) or socks5 proxy for the wallet's API requests
+	Proxies []string `json:"proxies"` // spare proxies, used in order while the ones before fail
+
+	// Mnemonic expands the entry into one wallet per index of DerivationPath, see Expand.
+	Mnemonic           string                     `json:"mnemonic"`
+	MnemonicPassphrase string                     `json:"mnemonic_passphrase"`
+	DerivationPath     string                     `json:"derivation_path"`
+	Overrides          map[string]json.RawMessage `json:"overrides"`
 }
 
 type NFTCategories struct {
@@ -37,21 +49,33 @@ type NFTCategories struct {
 	Zora   map[string]string `json:"zora"`
 }
 
-type ModulesConfig struct {
-	Uniswap   bool `json:"uniswap"`
-	Pancake   bool `json:"pancake"`
-	Woofi     bool `json:"woofi"`
-	OpenOcean bool `json:"openocean"`
-	Odos      bool `json:"odos"`
-	Refuel    bool `json:"refuel"`
-	Zora      bool `json:"zora"`
-	NFT2Me    bool `json:"nft2me"`
-	BaseNames bool `json:"basenames"`
-	Stargate  bool `json:"stargate"`
-	Dmail     bool `json:"dmail"`
-	Aave      bool `json:"aave"`
-	Moonwell  bool `json:"moonwell"`
-	Collector bool `json:"collector_mod"`
+// KeyRef is how the wallet is looked up in the keystore: its address, or else its label.
+func (wc *WalletConfig) KeyRef() string {
+	if ref := strings.TrimSpace(wc.Address); ref != "" {
+		return ref
+	}
+	return strings.TrimSpace(wc.Label)
+}
+
+// ProxyList is the wallet's proxy followed by its spares, without blanks and repeats.
+func (wc *WalletConfig) ProxyList() []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, proxy := range append([]string{wc.Proxy}, wc.Proxies...) {
+		if proxy = strings.TrimSpace(proxy); proxy != "" && !seen[proxy] {
+			seen[proxy] = true
+			list = append(list, proxy)
+		}
+	}
+	return list
+}
+
+// ModulesConfig maps a module config key to whether the module is enabled. Keys are defined by
+// the modules registered in the actions package.
+type ModulesConfig map[string]bool
+
+func (m ModulesConfig) Enabled(key string) bool {
+	return m[key]
 }
 
 func LoadRandomConfig(path string) (*RandomConfig, error) {
@@ -70,11 +94,11 @@ func LoadRandomConfig(path string) (*RandomConfig, error) {
 func InitializeAvailableNFTs(accConfig *RandomConfig) map[string]map[common.Address]*big.Int {
 	availableNFTs := make(map[string]map[common.Address]*big.Int)
 
-	if accConfig.Modules.NFT2Me {
+	if accConfig.Modules.Enabled("nft2me") {
 		processNFTCategory("nft2me", accConfig.NFTContracts.Nft2Me, availableNFTs)
 	}
 
-	if accConfig.Modules.Zora {
+	if accConfig.Modules.Enabled("zora") {
 		processNFTCategory("zora", accConfig.NFTContracts.Zora, availableNFTs)
 	}
 
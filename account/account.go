@@ -6,10 +6,13 @@ import (
 	"base/models"
 	"base/utils"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/sync/errgroup"
 )
@@ -37,6 +40,11 @@ type Account struct {
 	ActionNumMax     int
 	ActionTimeMIN    int
 	ActionTimeMAX    int
+	MaxGasPrice      *big.Int
+	Proxies          []string
+	CurrentAction    string
+	CurrentModule    string
+	SentTxs          []common.Hash
 }
 
 func NewAccount(accountID int, privateKey *ecdsa.PrivateKey, address common.Address, endpoint, baseName string, revert bool, usedRange, poolUsedRange int64, bridge, tokenBridge string, actionNumMin, actionNumMax, actionTimeMIN, actionTimeMAX int) *Account {
@@ -60,24 +68,58 @@ func NewAccount(accountID int, privateKey *ecdsa.PrivateKey, address common.Addr
 	}
 }
 
-func CreateAccounts(walletConfigs []WalletConfig) ([]*Account, error) {
+// SetCurrentAction labels the transactions sent from now on in the journal.
+func (a *Account) SetCurrentAction(action, module string) {
+	a.CurrentAction = action
+	a.CurrentModule = module
+}
+
+// RecordTxs remembers hashes broadcast for the current action.
+func (a *Account) RecordTxs(hashes ...common.Hash) {
+	a.SentTxs = append(a.SentTxs, hashes...)
+}
+
+// TakeTxs returns the recorded hashes and starts a new list.
+func (a *Account) TakeTxs() []common.Hash {
+	hashes := a.SentTxs
+	a.SentTxs = nil
+	return hashes
+}
+
+// KeySource resolves a wallet's address or label to its private key, see the vault package.
+type KeySource interface {
+	PrivateKey(ref string) (*ecdsa.PrivateKey, error)
+}
+
+// maxParallelDecrypts bounds how many keystore keys are decrypted at once. A standard scrypt
+// keystore takes about 256 MiB to decrypt, so this keeps the peak near 1 GiB on any machine.
+const maxParallelDecrypts = 4
+
+// CreateAccounts builds the accounts from wallets expanded with ExpandWallets. Mnemonic wallets
+// derive their key, wallets without private_key take it from keys by address or label; keys may
+// be nil when no wallet needs it.
+func CreateAccounts(walletConfigs []WalletConfig, keys KeySource) ([]*Account, error) {
 	var (
 		accounts     []*Account
 		accountsLock sync.Mutex
 	)
 
 	g := new(errgroup.Group)
+	g.SetLimit(maxParallelDecrypts)
 
 	for idx, wc := range walletConfigs {
 		idx := idx
 		wc := wc
 		g.Go(func() error {
-			privateKey, err := utils.ParsePrivateKey(wc.PrivateKey)
+			privateKey, err := walletKey(wc, keys)
 			if err != nil {
-				return fmt.Errorf("ошибка парсинга приватного ключа для кошелька %d: %v", idx+1, err)
+				return fmt.Errorf("ошибка получения приватного ключа для кошелька %d: %v", idx+1, err)
 			}
 
 			address := utils.DeriveAddress(privateKey)
+			if wc.Address != "" && common.HexToAddress(wc.Address) != address {
+				return fmt.Errorf("ключ кошелька %d принадлежит адресу %s, а не %s", idx+1, address.Hex(), wc.Address)
+			}
 
 			if wc.UsedRange == 0 {
 				wc.UsedRange = int64(70 + rand.Intn(31))
@@ -111,6 +153,11 @@ func CreateAccounts(walletConfigs []WalletConfig) ([]*Account, error) {
 				*wc.ActionTimeMAX,
 			)
 
+			if wc.MaxGasPriceGwei > 0 {
+				account.MaxGasPrice, _ = new(big.Float).Mul(big.NewFloat(wc.MaxGasPriceGwei), big.NewFloat(1e9)).Int(nil)
+			}
+			account.Proxies = wc.ProxyList()
+
 			accountsLock.Lock()
 			accounts = append(accounts, account)
 			accountsLock.Unlock()
@@ -129,3 +176,21 @@ func CreateAccounts(walletConfigs []WalletConfig) ([]*Account, error) {
 
 	return accounts, nil
 }
+
+func walletKey(wc WalletConfig, keys KeySource) (*ecdsa.PrivateKey, error) {
+	switch {
+	case wc.PrivateKey != "":
+		return utils.ParsePrivateKey(wc.PrivateKey)
+	case wc.Mnemonic != "":
+		path, err := accounts.ParseDerivationPath(wc.DerivationPath)
+		if err != nil {
+			return nil, err
+		}
+		return utils.DeriveHDKey(wc.Mnemonic, wc.MnemonicPassphrase, path)
+	case wc.KeyRef() == "":
+		return nil, errors.New("не задан ни private_key, ни address или label")
+	case keys == nil:
+		return nil, errors.New("хранилище ключей не открыто")
+	}
+	return keys.PrivateKey(wc.KeyRef())
+}
@@ -0,0 +1,178 @@
+package account
+
+import (
+	"base/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+)
+
+// maxDerivedWallets guards against a typo like 0..1999999 in a derivation range.
+const maxDerivedWallets = 10000
+
+var mnemonicEnvPattern = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// ExpandWallets replaces every mnemonic entry with the wallets it derives, in place, so the
+// wallets of an entry keep their IDs as long as the entries before it do not change.
+func ExpandWallets(wallets []WalletConfig) ([]WalletConfig, error) {
+	var expanded []WalletConfig
+	for i, wc := range wallets {
+		derived, err := wc.Expand()
+		if err != nil {
+			return nil, fmt.Errorf("кошелек %d: %v", i+1, err)
+		}
+		expanded = append(expanded, derived...)
+	}
+	return expanded, nil
+}
+
+// Expand turns a mnemonic entry into one wallet per index of its derivation path, e.g.
+// m/44'/60'/0'/0/0..199. Each wallet gets the entry's settings with its override, keyed by the
+// index, applied on top. An entry without a mnemonic is returned as it is.
+func (wc WalletConfig) Expand() ([]WalletConfig, error) {
+	if wc.Mnemonic == "" {
+		if len(wc.Overrides) > 0 || wc.DerivationPath != "" {
+			return nil, errors.New("derivation_path и overrides задаются только вместе с mnemonic")
+		}
+		return []WalletConfig{wc}, nil
+	}
+	if wc.PrivateKey != "" {
+		return nil, errors.New("задан и private_key, и mnemonic")
+	}
+
+	mnemonic, err := mnemonicValue(wc.Mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	prefix, from, to, err := parseDerivationRange(wc.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[uint32]json.RawMessage, len(wc.Overrides))
+	for key, raw := range wc.Overrides {
+		index, err := parseIndex(key)
+		if err != nil || index < from || index > to {
+			return nil, fmt.Errorf("override %s не входит в диапазон %s", key, wc.DerivationPath)
+		}
+		overrides[index] = raw
+	}
+
+	wallets := make([]WalletConfig, 0, to-from+1)
+	for index := from; ; index++ {
+		w := wc
+		w.Mnemonic = mnemonic
+		w.DerivationPath = append(append(accounts.DerivationPath{}, prefix...), index).String()
+		w.Overrides = nil
+
+		if raw, ok := overrides[index]; ok {
+			if err := w.applyOverride(raw); err != nil {
+				return nil, fmt.Errorf("override %s: %v", indexString(index), err)
+			}
+		}
+		wallets = append(wallets, w)
+
+		// the index may be the last uint32, so the loop stops here instead of on index > to
+		if index == to {
+			break
+		}
+	}
+	return wallets, nil
+}
+
+// applyOverride sets the fields present in raw. The pointer fields are copied first, so the
+// override does not write through them into the other wallets of the entry.
+func (wc *WalletConfig) applyOverride(raw json.RawMessage) error {
+	for _, p := range []**int{&wc.ActionNumMIN, &wc.ActionNumMAX, &wc.ActionTimeMIN, &wc.ActionTimeMAX} {
+		if *p != nil {
+			v := **p
+			*p = &v
+		}
+	}
+
+	mnemonic, passphrase, path := wc.Mnemonic, wc.MnemonicPassphrase, wc.DerivationPath
+	if err := json.Unmarshal(raw, wc); err != nil {
+		return err
+	}
+	if wc.PrivateKey != "" || wc.Mnemonic != mnemonic || wc.MnemonicPassphrase != passphrase || wc.DerivationPath != path || wc.Overrides != nil {
+		return errors.New("ключ кошелька в override не меняется, можно задать только address для проверки")
+	}
+	return nil
+}
+
+// mnemonicValue reads a ${VAR} mnemonic from the environment and normalizes the phrase.
+func mnemonicValue(value string) (string, error) {
+	if m := mnemonicEnvPattern.FindStringSubmatch(strings.TrimSpace(value)); m != nil {
+		env, ok := os.LookupEnv(m[1])
+		if !ok {
+			return "", fmt.Errorf("переменная окружения %s с мнемоникой не задана", m[1])
+		}
+		value = env
+	}
+	return utils.NormalizeMnemonic(value)
+}
+
+// parseDerivationRange splits a path like m/44'/60'/0'/0/0..199 into its fixed prefix and the
+// first and last index of its last component. A path without a range is a single wallet, and
+// an empty path is m/44'/60'/0'/0/0.
+func parseDerivationRange(path string) (accounts.DerivationPath, uint32, uint32, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return accounts.DefaultBaseDerivationPath[:4], 0, 0, nil
+	}
+
+	slash := strings.LastIndex(path, "/")
+	if slash < 0 {
+		return nil, 0, 0, fmt.Errorf("некорректный derivation_path %s", path)
+	}
+	prefix, last := path[:slash], path[slash+1:]
+	first, final, isRange := strings.Cut(last, "..")
+	if !isRange {
+		final = first
+	}
+
+	start, err := accounts.ParseDerivationPath(prefix + "/" + strings.TrimSpace(first))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("некорректный derivation_path %s: %v", path, err)
+	}
+	end, err := accounts.ParseDerivationPath(prefix + "/" + strings.TrimSpace(final))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("некорректный derivation_path %s: %v", path, err)
+	}
+
+	from, to := start[len(start)-1], end[len(end)-1]
+	if (from >= 0x80000000) != (to >= 0x80000000) || from > to {
+		return nil, 0, 0, fmt.Errorf("некорректный диапазон в derivation_path %s", path)
+	}
+	if to-from >= maxDerivedWallets {
+		return nil, 0, 0, fmt.Errorf("диапазон в derivation_path %s больше %d кошельков", path, maxDerivedWallets)
+	}
+	return start[:len(start)-1], from, to, nil
+}
+
+// parseIndex reads an override key: the index in the last path component, with ' when hardened.
+func parseIndex(key string) (uint32, error) {
+	key = strings.TrimSpace(key)
+	var hardened uint32
+	if strings.HasSuffix(key, "'") {
+		key, hardened = strings.TrimSuffix(key, "'"), 0x80000000
+	}
+	index, err := strconv.ParseUint(key, 10, 31)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(index) + hardened, nil
+}
+
+func indexString(index uint32) string {
+	if index >= 0x80000000 {
+		return strconv.FormatUint(uint64(index-0x80000000), 10) + "'"
+	}
+	return strconv.FormatUint(uint64(index), 10)
+}
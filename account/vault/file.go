@@ -0,0 +1,251 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	fileVersion = 1
+
+	// the parameters go-ethereum uses for keystore files
+	scryptN      = 1 << 18
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// File is a single vault file: every key with its label, encrypted with AES-256-GCM under a
+// key derived from the passphrase with scrypt. Nothing, not even the addresses, is readable
+// without the passphrase.
+type File struct {
+	mu         sync.Mutex
+	path       string
+	passphrase string
+	wallets    []fileWallet
+}
+
+type fileWallet struct {
+	Label      string `json:"label,omitempty"`
+	Address    string `json:"address"`
+	PrivateKey string `json:"private_key"`
+}
+
+type fileFormat struct {
+	Version    int     `json:"version"`
+	KDF        kdfSpec `json:"kdf"`
+	Nonce      string  `json:"nonce"`
+	Ciphertext string  `json:"ciphertext"`
+}
+
+type kdfSpec struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// OpenFile decrypts the vault at path. A missing file is an empty vault that is created on the
+// first import.
+func OpenFile(path, passphrase string) (*File, error) {
+	f := &File{path: path, passphrase: passphrase}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var format fileFormat
+	if err := json.Unmarshal(data, &format); err != nil {
+		return nil, fmt.Errorf("поврежденный файл хранилища %s: %v", path, err)
+	}
+	if format.Version != fileVersion || format.KDF.Name != "scrypt" {
+		return nil, fmt.Errorf("неподдерживаемый формат хранилища %s: version %d, kdf %s", path, format.Version, format.KDF.Name)
+	}
+
+	plaintext, err := decrypt(format, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(plaintext, &f.wallets); err != nil {
+		return nil, fmt.Errorf("поврежденное содержимое хранилища %s: %v", path, err)
+	}
+
+	return f, nil
+}
+
+func (f *File) PrivateKey(ref string) (*ecdsa.PrivateKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.find(ref)
+	if i < 0 {
+		return nil, notFound(ref)
+	}
+	return crypto.HexToECDSA(f.wallets[i].PrivateKey)
+}
+
+func (f *File) List() ([]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries := make([]Entry, len(f.wallets))
+	for i, w := range f.wallets {
+		entries[i] = Entry{Label: w.Label, Address: common.HexToAddress(w.Address)}
+	}
+	return entries, nil
+}
+
+func (f *File) Import(key *ecdsa.PrivateKey, label string) (common.Address, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	label = strings.TrimSpace(label)
+	if label != "" {
+		if _, isAddress := parseRef(label); isAddress {
+			return common.Address{}, fmt.Errorf("метка %s не может быть адресом", label)
+		}
+		if i := f.find(label); i >= 0 && !strings.EqualFold(f.wallets[i].Address, address.Hex()) {
+			return common.Address{}, fmt.Errorf("метка %s уже занята кошельком %s", label, f.wallets[i].Address)
+		}
+	}
+
+	wallet := fileWallet{Label: label, Address: address.Hex(), PrivateKey: hex.EncodeToString(crypto.FromECDSA(key))}
+	if i := f.find(address.Hex()); i >= 0 {
+		f.wallets[i] = wallet
+	} else {
+		f.wallets = append(f.wallets, wallet)
+	}
+
+	return address, f.save(f.passphrase)
+}
+
+func (f *File) Reencrypt(newPassphrase string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.save(newPassphrase); err != nil {
+		return err
+	}
+	f.passphrase = newPassphrase
+	return nil
+}
+
+// find returns the index of the wallet with the address or label, or -1.
+func (f *File) find(ref string) int {
+	address, isAddress := parseRef(ref)
+	for i, w := range f.wallets {
+		if isAddress && common.HexToAddress(w.Address) == address {
+			return i
+		}
+		if !isAddress && w.Label != "" && w.Label == strings.TrimSpace(ref) {
+			return i
+		}
+	}
+	return -1
+}
+
+// save encrypts the wallets with a fresh salt and nonce and replaces the file atomically.
+func (f *File) save(passphrase string) error {
+	plaintext, err := json.Marshal(f.wallets)
+	if err != nil {
+		return err
+	}
+	format, err := encrypt(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(format, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func encrypt(plaintext []byte, passphrase string) (fileFormat, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return fileFormat{}, err
+	}
+	kdf := kdfSpec{Name: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: hex.EncodeToString(salt)}
+
+	aead, err := newAEAD(kdf, passphrase)
+	if err != nil {
+		return fileFormat{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fileFormat{}, err
+	}
+
+	return fileFormat{
+		Version:    fileVersion,
+		KDF:        kdf,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}, nil
+}
+
+func decrypt(format fileFormat, passphrase string) ([]byte, error) {
+	aead, err := newAEAD(format.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(format.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, errors.New("некорректный nonce в хранилище")
+	}
+	ciphertext, err := hex.DecodeString(format.Ciphertext)
+	if err != nil {
+		return nil, errors.New("некорректные данные в хранилище")
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("неверный пароль хранилища ключей")
+	}
+	return plaintext, nil
+}
+
+func newAEAD(kdf kdfSpec, passphrase string) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(kdf.Salt)
+	if err != nil {
+		return nil, errors.New("некорректная соль в хранилище")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, kdf.N, kdf.R, kdf.P, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,124 @@
+package vault
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KeystoreDir is a directory of go-ethereum keystore v3 files, one per wallet, as written by
+// geth, Clef or MetaMask exports. The files carry no labels, so wallets are referenced by
+// address, and each key is decrypted only when it is asked for.
+type KeystoreDir struct {
+	dir        string
+	ks         *keystore.KeyStore
+	passphrase string
+}
+
+func OpenKeystoreDir(dir, passphrase string) (*KeystoreDir, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &KeystoreDir{
+		dir:        dir,
+		ks:         keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP),
+		passphrase: passphrase,
+	}, nil
+}
+
+func (d *KeystoreDir) PrivateKey(ref string) (*ecdsa.PrivateKey, error) {
+	account, err := d.find(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(data, d.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать ключ %s: %v", account.Address.Hex(), err)
+	}
+	return key.PrivateKey, nil
+}
+
+func (d *KeystoreDir) List() ([]Entry, error) {
+	var entries []Entry
+	for _, account := range d.ks.Accounts() {
+		entries = append(entries, Entry{Address: account.Address})
+	}
+	return entries, nil
+}
+
+// Import ignores the label, v3 files have no place for it.
+func (d *KeystoreDir) Import(key *ecdsa.PrivateKey, label string) (common.Address, error) {
+	account, err := d.ks.ImportECDSA(key, d.passphrase)
+	if errors.Is(err, keystore.ErrAccountAlreadyExists) {
+		return account.Address, nil
+	}
+	return account.Address, err
+}
+
+// Reencrypt first writes every key, encrypted with the new passphrase, into a hidden temporary
+// directory, which the keystore does not scan, so a wrong passphrase or a failed write leaves all
+// files as they were. Only then the files are moved over the old ones.
+func (d *KeystoreDir) Reencrypt(newPassphrase string) error {
+	tmp, err := os.MkdirTemp(d.dir, ".reencrypt-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	wallets := d.ks.Accounts()
+	for _, account := range wallets {
+		data, err := os.ReadFile(account.URL.Path)
+		if err != nil {
+			return err
+		}
+		key, err := keystore.DecryptKey(data, d.passphrase)
+		if err != nil {
+			return fmt.Errorf("не удалось расшифровать ключ %s: %v", account.Address.Hex(), err)
+		}
+		encrypted, err := keystore.EncryptKey(key, newPassphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+		if err != nil {
+			return fmt.Errorf("не удалось перешифровать ключ %s: %v", account.Address.Hex(), err)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, filepath.Base(account.URL.Path)), encrypted, 0600); err != nil {
+			return err
+		}
+	}
+
+	var moved []string
+	for _, account := range wallets {
+		if err := os.Rename(filepath.Join(tmp, filepath.Base(account.URL.Path)), account.URL.Path); err != nil {
+			if len(moved) == 0 {
+				return err
+			}
+			return fmt.Errorf("%v. Новым паролем уже зашифрованы ключи: %s", err, strings.Join(moved, ", "))
+		}
+		moved = append(moved, account.Address.Hex())
+	}
+
+	d.passphrase = newPassphrase
+	return nil
+}
+
+func (d *KeystoreDir) find(ref string) (accounts.Account, error) {
+	address, ok := parseRef(ref)
+	if !ok {
+		return accounts.Account{}, fmt.Errorf("в папке keystore кошельки указываются адресом, а не меткой: %s", ref)
+	}
+	account, err := d.ks.Find(accounts.Account{Address: address})
+	if err != nil {
+		return accounts.Account{}, notFound(ref)
+	}
+	return account, nil
+}
@@ -0,0 +1,65 @@
+package vault
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Entry is a wallet in the store, without its key.
+type Entry struct {
+	Label   string
+	Address common.Address
+}
+
+// Store keeps private keys encrypted with a passphrase. Wallets are looked up by address or,
+// where the store keeps labels, by label.
+type Store interface {
+	PrivateKey(ref string) (*ecdsa.PrivateKey, error)
+	List() ([]Entry, error)
+	// Import encrypts the key and adds it, replacing the label of a wallet that is already there.
+	Import(key *ecdsa.PrivateKey, label string) (common.Address, error)
+	// Reencrypt encrypts every key with a new passphrase.
+	Reencrypt(newPassphrase string) error
+}
+
+// Open opens the store at path: a directory of go-ethereum keystore v3 files, or a single vault
+// file. A path that does not exist yet is a vault file when it ends with .json, a keystore
+// directory otherwise.
+func Open(path, passphrase string) (Store, error) {
+	if passphrase == "" {
+		return nil, errors.New("пустой пароль хранилища ключей")
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return OpenKeystoreDir(path, passphrase)
+	case err == nil:
+		return OpenFile(path, passphrase)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	case strings.EqualFold(filepath.Ext(path), ".json"):
+		return OpenFile(path, passphrase)
+	default:
+		return OpenKeystoreDir(path, passphrase)
+	}
+}
+
+// parseRef tells an address reference from a label.
+func parseRef(ref string) (common.Address, bool) {
+	ref = strings.TrimSpace(ref)
+	if !common.IsHexAddress(ref) {
+		return common.Address{}, false
+	}
+	return common.HexToAddress(ref), true
+}
+
+func notFound(ref string) error {
+	return fmt.Errorf("кошелек %s не найден в хранилище ключей", ref)
+}
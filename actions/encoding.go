@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"base/actions/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// actionJSON is the encoding of an action: its type and only the params its module uses.
+type actionJSON struct {
+	Type   types.ActionType `json:"type"`
+	Params json.RawMessage  `json:"params,omitempty"`
+}
+
+func (a Action) MarshalJSON() ([]byte, error) {
+	m, ok := ModuleFor(a.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown action type %q", a.Type)
+	}
+
+	out := actionJSON{Type: a.Type}
+	if m.ParamsOf != nil {
+		params, err := json.Marshal(m.ParamsOf(&a))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s params: %v", a.Type, err)
+		}
+		out.Params = params
+	}
+	return json.Marshal(out)
+}
+
+func (a *Action) UnmarshalJSON(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	if _, ok := keys["type"]; !ok {
+		return a.unmarshalLegacy(data)
+	}
+
+	var in actionJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	m, ok := ModuleFor(in.Type)
+	if !ok {
+		return fmt.Errorf("unknown action type %q", in.Type)
+	}
+
+	*a = Action{Type: in.Type}
+	if m.ParamsOf == nil || len(in.Params) == 0 || bytes.Equal(in.Params, []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(in.Params, m.ParamsOf(a)); err != nil {
+		return fmt.Errorf("failed to decode %s params: %v", in.Type, err)
+	}
+	return nil
+}
+
+// unmarshalLegacy reads the old encoding, where every params struct was stored under its Go
+// field name next to "Type".
+func (a *Action) unmarshalLegacy(data []byte) error {
+	type legacy Action
+	var l legacy
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	if _, ok := ModuleFor(l.Type); !ok {
+		return fmt.Errorf("unknown action type %q", l.Type)
+	}
+	*a = Action(l)
+	return nil
+}
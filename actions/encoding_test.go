@@ -0,0 +1,134 @@
+package actions_test
+
+import (
+	"base/actions"
+	"base/actions/handlers"
+	"base/actions/types"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fullAction has every params struct filled, amounts are above 2^53 so that a float64 on the
+// way would lose them.
+func fullAction(actionType types.ActionType) actions.Action {
+	amount, _ := new(big.Int).SetString("9007199254740993", 10)
+	wei, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	return actions.Action{
+		Type: actionType,
+		DexParams: types.DexParams{
+			FromToken:    common.HexToAddress("0x4200000000000000000000000000000000000006"),
+			ToToken:      common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+			AmountToSwap: wei,
+		},
+		NftMintParams: types.NftMintParams{MintCA: common.HexToAddress("0x01"), Price: amount},
+		BridgeParams:  types.BridgeParams{FromChain: "arbitrum", DstChain: "base", Token: "usdc", AmountToBridge: wei},
+		RefuelParams:  types.RefuelParams{DstChain: "base", ScrChain: "optimism", AmountToBridge: amount},
+		LiquidParams:  types.LiquidParams{Type: "supply", Token: common.HexToAddress("0x02"), Amount: wei},
+		BSNParams:     types.BSNParams{Name: "wallet42"},
+	}
+}
+
+func TestActionRoundTrip(t *testing.T) {
+	registered := make(map[types.ActionType]bool)
+	for _, m := range actions.Modules() {
+		for _, actionType := range m.Actions {
+			registered[actionType] = true
+			full := fullAction(actionType)
+
+			// only the params of the action's own module survive the round trip
+			want := actions.Action{Type: actionType}
+			if m.ParamsOf != nil {
+				reflect.ValueOf(m.ParamsOf(&want)).Elem().Set(reflect.ValueOf(m.ParamsOf(&full)).Elem())
+			}
+
+			data, err := json.Marshal(full)
+			if err != nil {
+				t.Fatalf("%s: Marshal: %v", actionType, err)
+			}
+			var got actions.Action
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("%s: Unmarshal(%s): %v", actionType, data, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: round trip of %s\ngot  %+v\nwant %+v", actionType, data, got, want)
+			}
+		}
+	}
+
+	for _, actionType := range []types.ActionType{
+		handlers.UniswapAction, handlers.PancakeAction, handlers.WoofiAction, handlers.OdosAction,
+		handlers.OpenOceanAction, handlers.AaveETHDepositAction, handlers.AaveETHWithdrawAction,
+		handlers.AaveUSDCSupplyAction, handlers.AaveUSDCWithdrawAction, handlers.MoonwellDepositAction,
+		handlers.MoonwellWithdrawAction, handlers.BridgeAction, handlers.RefuelAction, handlers.ZoraAction,
+		handlers.NFT2MeAction, handlers.BaseNameAction, handlers.DmailAction, handlers.CollectorModAction,
+	} {
+		if !registered[actionType] {
+			t.Errorf("%s is not registered", actionType)
+		}
+	}
+}
+
+func TestActionEncodingIsTagged(t *testing.T) {
+	data, err := json.Marshal(fullAction(handlers.BaseNameAction))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"type":"basenames","params":{"name":"wallet42"}}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	data, err = json.Marshal(fullAction(handlers.DmailAction))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"type":"dmail"}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+}
+
+func TestActionDecodesLegacyFormat(t *testing.T) {
+	// state files written before the tagged encoding stored every params struct under its Go
+	// field name, with amounts as bare JSON numbers
+	legacy := `{
+		"Type": "uniswap",
+		"DexParams": {
+			"FromToken": "0x4200000000000000000000000000000000000006",
+			"ToToken": "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+			"AmountToSwap": 123456789012345678901234567890
+		},
+		"NftMintParams": {"MintCA": "0x0000000000000000000000000000000000000000", "Price": null},
+		"BridgeParams": {"FromChain": "", "DstChain": "", "Token": "", "AmountToBridge": null},
+		"RefuelParams": {"DstChain": "", "ScrChain": "", "AmountToBridge": null},
+		"LiquidParams": {"Type": "", "Token": "0x0000000000000000000000000000000000000000", "Amount": null},
+		"BSNParams": {"Name": ""}
+	}`
+
+	var got actions.Action
+	if err := json.Unmarshal([]byte(legacy), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	full := fullAction(handlers.UniswapAction)
+	want := actions.Action{Type: handlers.UniswapAction, DexParams: full.DexParams}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("legacy decode\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestActionUnknownType(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"no_such_action"}`,
+		`{"Type":"no_such_action"}`,
+	} {
+		var got actions.Action
+		if err := json.Unmarshal([]byte(data), &got); err == nil {
+			t.Errorf("Unmarshal(%s) accepted an unknown type", data)
+		}
+	}
+	if _, err := json.Marshal(actions.Action{Type: "no_such_action"}); err == nil {
+		t.Error("Marshal accepted an unknown type")
+	}
+}
@@ -2,88 +2,118 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	cfg "base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/liquid_pools/aave"
+	"context"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+const (
+	AaveETHDepositAction   types.ActionType = "aave_deposit"
+	AaveETHWithdrawAction  types.ActionType = "aave_withdraw"
+	AaveUSDCSupplyAction   types.ActionType = "aave_supply"
+	AaveUSDCWithdrawAction types.ActionType = "aave_withdraw_usdc"
+)
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "aave",
+		ConfigKey: "aave",
+		Actions:   []types.ActionType{AaveETHDepositAction, AaveETHWithdrawAction, AaveUSDCSupplyAction, AaveUSDCWithdrawAction},
+		Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+			return src.PoolAction(ctx, actionType, acc)
+		},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.LiquidParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return AaveHandler{LiquidParams: action.LiquidParams}
+		},
+	})
+}
+
 type AaveHandler struct {
 	LiquidParams types.LiquidParams
 }
 
-func (ah AaveHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
+func (ah AaveHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
 	switch ah.LiquidParams.Type {
-	case string(types.AaveETHDepositAction):
-		return ah.handleDeposit(acc, mods, client)
-	case string(types.AaveETHWithdrawAction):
-		return ah.handleWithdrawETH(acc, client, mods)
-	case string(types.AaveUSDCSupplyAction):
-		return ah.handleSupply(acc, mods, client)
-	case string(types.AaveUSDCWithdrawAction):
-		return ah.handleWithdrawSpecific(acc, client, mods)
+	case string(AaveETHDepositAction):
+		return ah.handleDeposit(ctx, acc, mods, client)
+	case string(AaveETHWithdrawAction):
+		return ah.handleWithdrawETH(ctx, acc, client, mods)
+	case string(AaveUSDCSupplyAction):
+		return ah.handleSupply(ctx, acc, mods, client)
+	case string(AaveUSDCWithdrawAction):
+		return ah.handleWithdrawSpecific(ctx, acc, client, mods)
 	default:
 		return fmt.Errorf("uncknow action type: %s", ah.LiquidParams.Type)
 	}
 }
 
-func (ah AaveHandler) handleDeposit(acc *account.Account, mods modules.Modules, client *ethClient.Client) error {
-	amount, err := ah.calculateAmountToDeposit(acc, client, cfg.WETH)
+func (ah AaveHandler) handleDeposit(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client) error {
+	amount, err := ah.calculateAmountToDeposit(ctx, acc, client, cfg.Chains.Base.WETH)
 	if err != nil {
 		return err
 	}
 
-	return mods.LiquidPools.Aave.DepositETH(amount, acc)
+	return modules.Get[*aave.Aave](mods, aave.Module).DepositETH(ctx, amount, acc)
 }
 
-func (ah AaveHandler) handleWithdrawETH(acc *account.Account, client *ethClient.Client, mods modules.Modules) error {
-	amount, err := client.BalanceCheck(acc.Address, cfg.AaveWETH)
+func (ah AaveHandler) handleWithdrawETH(ctx context.Context, acc *account.Account, client *ethClient.Client, mods modules.Modules) error {
+	pool := modules.Get[*aave.Aave](mods, aave.Module)
+	amount, err := client.BalanceCheck(ctx, acc.Address, cfg.Chains.Base.AaveWETH)
 	if err != nil {
 		return err
 	}
 
-	if err := ah.ensureApproval(client, acc, cfg.AaveWETH, mods.LiquidPools.Aave.EthPool, amount); err != nil {
+	if err := ah.ensureApproval(ctx, client, acc, cfg.Chains.Base.AaveWETH, pool.EthPool, amount); err != nil {
 		return err
 	}
 
-	return mods.LiquidPools.Aave.WithdrawETH(acc, amount)
+	return pool.WithdrawETH(ctx, acc, amount)
 }
 
-func (ah AaveHandler) handleSupply(acc *account.Account, mods modules.Modules, client *ethClient.Client) error {
-	amount, err := ah.calculateAmountToDeposit(acc, client, cfg.USDC)
+func (ah AaveHandler) handleSupply(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client) error {
+	pool := modules.Get[*aave.Aave](mods, aave.Module)
+	amount, err := ah.calculateAmountToDeposit(ctx, acc, client, cfg.Chains.Base.USDC)
 	if err != nil {
 		return err
 	}
 
-	if err := ah.ensureApproval(client, acc, cfg.USDC, mods.LiquidPools.Aave.ProxyBase, amount); err != nil {
+	if err := ah.ensureApproval(ctx, client, acc, cfg.Chains.Base.USDC, pool.ProxyBase, amount); err != nil {
 		return err
 	}
 
-	return mods.LiquidPools.Aave.Supply(acc, cfg.USDC, amount)
+	return pool.Supply(ctx, acc, cfg.Chains.Base.USDC, amount)
 }
 
-func (ah AaveHandler) handleWithdrawSpecific(acc *account.Account, client *ethClient.Client, mods modules.Modules) error {
-	amount, err := client.BalanceCheck(acc.Address, cfg.AaveUSDC)
+func (ah AaveHandler) handleWithdrawSpecific(ctx context.Context, acc *account.Account, client *ethClient.Client, mods modules.Modules) error {
+	pool := modules.Get[*aave.Aave](mods, aave.Module)
+	amount, err := client.BalanceCheck(ctx, acc.Address, cfg.Chains.Base.AaveUSDC)
 	if err != nil {
 		return err
 	}
 
-	if err := ah.ensureApproval(client, acc, cfg.AaveUSDC, mods.LiquidPools.Aave.ProxyBase, amount); err != nil {
+	if err := ah.ensureApproval(ctx, client, acc, cfg.Chains.Base.AaveUSDC, pool.ProxyBase, amount); err != nil {
 		return err
 	}
 
-	return mods.LiquidPools.Aave.Withdraw(acc, cfg.USDC)
+	return pool.Withdraw(ctx, acc, cfg.Chains.Base.USDC)
 }
 
-func (ah AaveHandler) calculateAmountToDeposit(acc *account.Account, client *ethClient.Client, token common.Address) (*big.Int, error) {
-	return CalculatePercentageOfBalance(acc, client, token, acc.PoolUsedRange, []common.Address{cfg.WETH})
+func (ah AaveHandler) calculateAmountToDeposit(ctx context.Context, acc *account.Account, client *ethClient.Client, token common.Address) (*big.Int, error) {
+	return CalculatePercentageOfBalance(ctx, acc, client, token, acc.PoolUsedRange, []common.Address{cfg.Chains.Base.WETH})
 }
 
-func (ah AaveHandler) ensureApproval(client *ethClient.Client, acc *account.Account, tokenAddr, spender common.Address, amount *big.Int) error {
-	_, err := client.ApproveTx(tokenAddr, spender, acc, amount, false)
+func (ah AaveHandler) ensureApproval(ctx context.Context, client *ethClient.Client, acc *account.Account, tokenAddr, spender common.Address, amount *big.Int) error {
+	_, err := client.ApproveTx(ctx, tokenAddr, spender, acc, amount, false)
 	return err
 }
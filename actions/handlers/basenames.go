@@ -2,24 +2,50 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
+	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/domains"
 	"context"
 	"errors"
 	"math/big"
+	"strings"
 )
 
+const BaseNameAction types.ActionType = "basenames"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "basenames",
+		ConfigKey: "basenames",
+		Actions:   []types.ActionType{BaseNameAction},
+		Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+			return actions.Action{Type: actionType, BSNParams: types.BSNParams{Name: acc.BaseName}}, nil
+		},
+		Available: func(wallet *account.WalletConfig) bool {
+			return strings.TrimSpace(wallet.BaseName) != ""
+		},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.BSNParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return BaseNameHandler{}
+		},
+	})
+}
+
 type BaseNameHandler struct {
 }
 
-func (bh BaseNameHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+func (bh BaseNameHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
 	price, err := bh.calculatePrice(acc.BaseName)
 	if err != nil {
 		return err
 	}
 
-	balance, err := client.Client.BalanceAt(context.Background(), acc.Address, nil)
+	balance, err := client.Client.BalanceAt(ctx, acc.Address, nil)
 	if err != nil {
 		return err
 	}
@@ -28,7 +54,7 @@ func (bh BaseNameHandler) Execute(acc *account.Account, mods modules.Modules, cl
 		return errors.New("insufficient balance to register a name")
 	}
 
-	return mods.Domains.RegisterName(acc.BaseName, price, acc)
+	return modules.Get[*domains.BSN](mods, domains.BSNModule).RegisterName(ctx, acc.BaseName, price, acc)
 }
 
 func (bh BaseNameHandler) calculatePrice(name string) (*big.Int, error) {
@@ -2,16 +2,35 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/bridge"
+	"context"
 )
 
+const BridgeAction types.ActionType = "stargate"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "stargate",
+		ConfigKey: "stargate",
+		Actions:   []types.ActionType{BridgeAction},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.BridgeParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return BridgeHandler{BridgeParams: action.BridgeParams}
+		},
+	})
+}
+
 type BridgeHandler struct {
 	BridgeParams types.BridgeParams
 }
 
-func (bh BridgeHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
-	return mods.Bridge.SwapStable(bh.BridgeParams.FromChain, bh.BridgeParams.DstChain, bh.BridgeParams.Token, bh.BridgeParams.AmountToBridge, acc)
+func (bh BridgeHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+	return modules.Get[*bridge.Stargate](mods, bridge.StargateModule).SwapStable(ctx, bh.BridgeParams.FromChain, bh.BridgeParams.DstChain, bh.BridgeParams.Token, bh.BridgeParams.AmountToBridge, acc)
 }
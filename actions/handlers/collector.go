@@ -2,13 +2,30 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
+	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/collector"
+	"context"
 )
 
+const CollectorModAction types.ActionType = "collector_mod"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "collector_mod",
+		ConfigKey: "collector_mod",
+		Actions:   []types.ActionType{CollectorModAction},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return &CollectorHandler{}
+		},
+	})
+}
+
 type CollectorHandler struct{}
 
-func (c *CollectorHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
-	return mods.Collector.Collect(acc)
+func (c *CollectorHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+	return modules.Get[*collector.Collector](mods, collector.Module).Collect(ctx, acc)
 }
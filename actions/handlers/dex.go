@@ -2,29 +2,60 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	cfg "base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/dex"
 	"base/utils"
+	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+const (
+	UniswapAction   types.ActionType = "uniswap"
+	PancakeAction   types.ActionType = "pancake"
+	WoofiAction     types.ActionType = "woofi"
+	OdosAction      types.ActionType = "odos"
+	OpenOceanAction types.ActionType = "openocean"
+)
+
+func init() {
+	for _, dex := range []types.ActionType{UniswapAction, PancakeAction, WoofiAction, OdosAction, OpenOceanAction} {
+		actions.Register(actions.Module{
+			Name:      string(dex),
+			ConfigKey: string(dex),
+			Actions:   []types.ActionType{dex},
+			Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+				return src.SwapAction(ctx, actionType, acc)
+			},
+			ParamsOf: func(action *actions.Action) any {
+				return &action.DexParams
+			},
+			Handler: func(action actions.Action) actions.ActionHandler {
+				return DexHandler{DexParams: action.DexParams, ActionType: action.Type}
+			},
+		})
+	}
+}
+
 type DexHandler struct {
 	DexParams  types.DexParams
 	ActionType types.ActionType
 }
 
-func (dh DexHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
-	amountToSwap, err := dh.calculateAmountToSwap(acc, client)
+func (dh DexHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
+	amountToSwap, err := dh.calculateAmountToSwap(ctx, acc, client)
 	if err != nil {
 		return err
 	}
 	if amountToSwap.Cmp(big.NewInt(0)) == 0 {
-		return errors.New("invalid amount to swap")
+		return fmt.Errorf("invalid amount to swap: %w", actions.ErrSkipped)
 	}
 
 	var value *big.Int
@@ -33,70 +64,70 @@ func (dh DexHandler) Execute(acc *account.Account, mods modules.Modules, client
 	}
 
 	switch dh.ActionType {
-	case types.UniswapAction:
-		dex := mods.Dex.Uniswap
-		if err := dh.ensureApproval(client, acc, mods.Dex.Uniswap.RouterCA, amountToSwap); err != nil {
+	case UniswapAction:
+		router := modules.Get[*dex.V3Router](mods, dex.UniswapModule)
+		if err := dh.ensureApproval(ctx, client, acc, router.RouterCA, amountToSwap); err != nil {
 			return err
 		}
 
 		if utils.IsNativeToken(dh.DexParams.ToToken) {
-			err = dex.SwapToETH(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, big.NewInt(0), acc)
+			err = router.SwapToETH(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, big.NewInt(0), acc)
 		} else {
-			err = dex.Swap(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, value, acc)
+			err = router.Swap(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, value, acc)
 		}
-	case types.PancakeAction:
-		dex := mods.Dex.Pancake
-		if err := dh.ensureApproval(client, acc, mods.Dex.Pancake.RouterCA, amountToSwap); err != nil {
+	case PancakeAction:
+		router := modules.Get[*dex.V3Router](mods, dex.PancakeModule)
+		if err := dh.ensureApproval(ctx, client, acc, router.RouterCA, amountToSwap); err != nil {
 			return err
 		}
 
 		if utils.IsNativeToken(dh.DexParams.ToToken) {
-			err = dex.SwapToETH(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, big.NewInt(0), acc)
+			err = router.SwapToETH(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, big.NewInt(0), acc)
 		} else {
-			err = dex.Swap(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, value, acc)
+			err = router.Swap(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, value, acc)
 		}
-	case types.WoofiAction:
-		dex := mods.Dex.Woofi
-		if dh.DexParams.FromToken == cfg.WETH {
-			dh.DexParams.FromToken = cfg.WooFiETH
+	case WoofiAction:
+		router := modules.Get[*dex.WooFi](mods, dex.WoofiModule)
+		if dh.DexParams.FromToken == cfg.Chains.Base.WETH {
+			dh.DexParams.FromToken = cfg.Chains.Base.ETH
 		}
-		if dh.DexParams.ToToken == cfg.WETH {
-			dh.DexParams.ToToken = cfg.WooFiETH
+		if dh.DexParams.ToToken == cfg.Chains.Base.WETH {
+			dh.DexParams.ToToken = cfg.Chains.Base.ETH
 		}
 
-		if err := dh.ensureApproval(client, acc, dex.CA, amountToSwap); err != nil {
+		if err := dh.ensureApproval(ctx, client, acc, router.CA, amountToSwap); err != nil {
 			return err
 		}
 
-		err = dex.Swap(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, value, acc)
-	case types.OdosAction:
-		dex := mods.Dex.Odos
-		if err := dh.ensureApproval(client, acc, mods.Dex.Odos.CA, amountToSwap); err != nil {
+		err = router.Swap(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, value, acc)
+	case OdosAction:
+		router := modules.Get[*dex.Odos](mods, dex.OdosModule)
+		if err := dh.ensureApproval(ctx, client, acc, router.CA, amountToSwap); err != nil {
 			return err
 		}
 
-		if dh.DexParams.FromToken == cfg.WETH {
+		if dh.DexParams.FromToken == cfg.Chains.Base.WETH {
 			dh.DexParams.FromToken = cfg.ZERO_ADDRESS
 		}
-		if dh.DexParams.ToToken == cfg.WETH {
+		if dh.DexParams.ToToken == cfg.Chains.Base.WETH {
 			dh.DexParams.ToToken = cfg.ZERO_ADDRESS
 		}
 
-		err = dex.Swap(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, acc)
-	case types.OpenOceanAction:
-		dex := mods.Dex.OpenOcean
-		if dh.DexParams.FromToken == cfg.WETH {
-			dh.DexParams.FromToken = cfg.WooFiETH
+		err = router.Swap(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, acc)
+	case OpenOceanAction:
+		router := modules.Get[*dex.OpenOcean](mods, dex.OpenOceanModule)
+		if dh.DexParams.FromToken == cfg.Chains.Base.WETH {
+			dh.DexParams.FromToken = cfg.Chains.Base.ETH
 		}
-		if dh.DexParams.ToToken == cfg.WETH {
-			dh.DexParams.ToToken = cfg.WooFiETH
+		if dh.DexParams.ToToken == cfg.Chains.Base.WETH {
+			dh.DexParams.ToToken = cfg.Chains.Base.ETH
 		}
 
-		if err := dh.ensureApproval(client, acc, mods.Dex.OpenOcean.CA, amountToSwap); err != nil {
+		if err := dh.ensureApproval(ctx, client, acc, router.CA, amountToSwap); err != nil {
 			return err
 		}
 
-		err = dex.Swap(dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, acc)
+		err = router.Swap(ctx, dh.DexParams.FromToken, dh.DexParams.ToToken, amountToSwap, acc)
 	default:
 		return errors.New("unsupported DEX action type")
 	}
@@ -104,11 +135,11 @@ func (dh DexHandler) Execute(acc *account.Account, mods modules.Modules, client
 	return err
 }
 
-func (dh *DexHandler) ensureApproval(client *ethClient.Client, acc *account.Account, routerCA common.Address, value *big.Int) error {
-	_, err := client.ApproveTx(dh.DexParams.FromToken, routerCA, acc, value, false)
+func (dh *DexHandler) ensureApproval(ctx context.Context, client *ethClient.Client, acc *account.Account, routerCA common.Address, value *big.Int) error {
+	_, err := client.ApproveTx(ctx, dh.DexParams.FromToken, routerCA, acc, value, false)
 	return err
 }
 
-func (dh *DexHandler) calculateAmountToSwap(acc *account.Account, client *ethClient.Client) (*big.Int, error) {
-	return CalculatePercentageOfBalance(acc, client, dh.DexParams.FromToken, acc.UsedRange, []common.Address{cfg.WETH, cfg.WooFiETH})
+func (dh *DexHandler) calculateAmountToSwap(ctx context.Context, acc *account.Account, client *ethClient.Client) (*big.Int, error) {
+	return CalculatePercentageOfBalance(ctx, acc, client, dh.DexParams.FromToken, acc.UsedRange, []common.Address{cfg.Chains.Base.WETH, cfg.Chains.Base.ETH})
 }
@@ -2,14 +2,31 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
+	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/dmail"
+	"context"
 )
 
+const DmailAction types.ActionType = "dmail"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "dmail",
+		ConfigKey: "dmail",
+		Actions:   []types.ActionType{DmailAction},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return DmailHandler{}
+		},
+	})
+}
+
 type DmailHandler struct {
 }
 
-func (dh DmailHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
-	return mods.Dmail.SendMail(acc)
+func (dh DmailHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+	return modules.Get[*dmail.Dmail](mods, dmail.Module).SendMail(ctx, acc)
 }
@@ -10,8 +10,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-func CalculatePercentageOfBalance(acc *account.Account, client *ethClient.Client, token common.Address, percentage int64, ethAddresses []common.Address) (*big.Int, error) {
-	balance, err := getTokenBalance(acc, client, token, ethAddresses)
+func CalculatePercentageOfBalance(ctx context.Context, acc *account.Account, client *ethClient.Client, token common.Address, percentage int64, ethAddresses []common.Address) (*big.Int, error) {
+	balance, err := getTokenBalance(ctx, acc, client, token, ethAddresses)
 	if err != nil {
 		return nil, err
 	}
@@ -22,7 +22,7 @@ func CalculatePercentageOfBalance(acc *account.Account, client *ethClient.Client
 	return amount, nil
 }
 
-func getTokenBalance(acc *account.Account, client *ethClient.Client, token common.Address, ethAddresses []common.Address) (*big.Int, error) {
+func getTokenBalance(ctx context.Context, acc *account.Account, client *ethClient.Client, token common.Address, ethAddresses []common.Address) (*big.Int, error) {
 	isETH := false
 	for _, ethAddr := range ethAddresses {
 		if token == ethAddr {
@@ -32,13 +32,13 @@ func getTokenBalance(acc *account.Account, client *ethClient.Client, token commo
 	}
 
 	if isETH {
-		balanceWei, err := client.Client.BalanceAt(context.Background(), acc.Address, nil)
+		balanceWei, err := client.Client.BalanceAt(ctx, acc.Address, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed get native balance: %v", err)
 		}
 		return balanceWei, nil
 	} else {
-		erc20Balance, err := client.BalanceCheck(acc.Address, token)
+		erc20Balance, err := client.BalanceCheck(ctx, acc.Address, token)
 		if err != nil {
 			return nil, fmt.Errorf("failed get balance erc20 token: %v", err)
 		}
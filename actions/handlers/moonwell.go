@@ -2,36 +2,62 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	cfg "base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/liquid_pools/moonwell"
+	"context"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+const (
+	MoonwellDepositAction  types.ActionType = "moonwell_deposit"
+	MoonwellWithdrawAction types.ActionType = "moonwell_withdraw"
+)
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "moonwell",
+		ConfigKey: "moonwell",
+		Actions:   []types.ActionType{MoonwellDepositAction, MoonwellWithdrawAction},
+		Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+			return src.PoolAction(ctx, actionType, acc)
+		},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.LiquidParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return MoonwellHandler{LiquidParams: action.LiquidParams}
+		},
+	})
+}
+
 type MoonwellHandler struct {
 	LiquidParams types.LiquidParams
 }
 
-func (mh MoonwellHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
-	amount, err := mh.CalculateAmountToDeposit(acc, client, cfg.WETH)
+func (mh MoonwellHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
+	amount, err := mh.CalculateAmountToDeposit(ctx, acc, client, cfg.Chains.Base.WETH)
 	if err != nil {
 		return err
 	}
 
+	pool := modules.Get[*moonwell.Moonwell](mods, moonwell.Module)
 	switch mh.LiquidParams.Type {
-	case string(types.MoonwellDepositAction):
-		return mods.LiquidPools.Moonwell.DepositETH(amount, acc)
-	case string(types.MoonwellWithdrawAction):
-		return mods.LiquidPools.Moonwell.WithdrawETH(acc, cfg.WETH)
+	case string(MoonwellDepositAction):
+		return pool.DepositETH(ctx, amount, acc)
+	case string(MoonwellWithdrawAction):
+		return pool.WithdrawETH(ctx, acc, cfg.Chains.Base.WETH)
 	default:
 		return fmt.Errorf("unknown action type: %s", mh.LiquidParams.Type)
 	}
 }
 
-func (mh MoonwellHandler) CalculateAmountToDeposit(acc *account.Account, client *ethClient.Client, token common.Address) (*big.Int, error) {
-	return CalculatePercentageOfBalance(acc, client, token, acc.PoolUsedRange, []common.Address{cfg.WETH})
+func (mh MoonwellHandler) CalculateAmountToDeposit(ctx context.Context, acc *account.Account, client *ethClient.Client, token common.Address) (*big.Int, error) {
+	return CalculatePercentageOfBalance(ctx, acc, client, token, acc.PoolUsedRange, []common.Address{cfg.Chains.Base.WETH})
 }
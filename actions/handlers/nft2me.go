@@ -2,17 +2,39 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/modules"
+	nftmints "base/modules/nft_mints"
+	"context"
 	"math/big"
 )
 
+const NFT2MeAction types.ActionType = "nft2me"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "nft2me",
+		ConfigKey: "nft2me",
+		Actions:   []types.ActionType{NFT2MeAction},
+		Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+			return src.NFTAction(actionType)
+		},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.NftMintParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return Nft2MeHandler{NftMintParams: action.NftMintParams}
+		},
+	})
+}
+
 type Nft2MeHandler struct {
 	NftMintParams types.NftMintParams
 }
 
-func (nh Nft2MeHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
-	return mods.NFTMints.NFT2Me.Mint(nh.NftMintParams.MintCA, big.NewInt(1), nh.NftMintParams.Price, acc)
+func (nh Nft2MeHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+	return modules.Get[*nftmints.Nft2Me](mods, nftmints.NFT2MeModule).Mint(ctx, nh.NftMintParams.MintCA, big.NewInt(1), nh.NftMintParams.Price, acc)
 }
@@ -2,16 +2,38 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	cfg "base/config"
 	"base/ethClient"
 	"base/modules"
+	"base/modules/refuel"
+	"context"
 )
 
+const RefuelAction types.ActionType = "refuel"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "refuel",
+		ConfigKey: "refuel",
+		Actions:   []types.ActionType{RefuelAction},
+		Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+			return src.RefuelAction(actionType, acc)
+		},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.RefuelParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return &RefuelHandler{RefuelParams: action.RefuelParams}
+		},
+	})
+}
+
 type RefuelHandler struct {
 	RefuelParams types.RefuelParams
 }
 
-func (rh *RefuelHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
-	return mods.Refuel.Refuel(rh.RefuelParams.ScrChain, rh.RefuelParams.DstChain, acc)
+func (rh *RefuelHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *cfg.Config) error {
+	return modules.Get[*refuel.Refuel](mods, refuel.Module).Refuel(ctx, rh.RefuelParams.ScrChain, rh.RefuelParams.DstChain, acc)
 }
@@ -2,16 +2,38 @@ package handlers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/modules"
+	nftmints "base/modules/nft_mints"
+	"context"
 )
 
+const ZoraAction types.ActionType = "zora"
+
+func init() {
+	actions.Register(actions.Module{
+		Name:      "zora",
+		ConfigKey: "zora",
+		Actions:   []types.ActionType{ZoraAction},
+		Params: func(ctx context.Context, src actions.ParamSource, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+			return src.NFTAction(actionType)
+		},
+		ParamsOf: func(action *actions.Action) any {
+			return &action.NftMintParams
+		},
+		Handler: func(action actions.Action) actions.ActionHandler {
+			return ZoraHandler{NftMintParams: action.NftMintParams}
+		},
+	})
+}
+
 type ZoraHandler struct {
 	NftMintParams types.NftMintParams
 }
 
-func (zh ZoraHandler) Execute(acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
-	return mods.NFTMints.Zora.Mint(zh.NftMintParams.MintCA, zh.NftMintParams.Price, acc)
+func (zh ZoraHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+	return modules.Get[*nftmints.Zora](mods, nftmints.ZoraModule).Mint(ctx, zh.NftMintParams.MintCA, zh.NftMintParams.Price, acc)
 }
@@ -1,6 +1,7 @@
 package randomization
 
 import (
+	"base/actions/handlers"
 	"base/actions/types"
 	"base/config"
 
@@ -39,7 +40,7 @@ func isValidPoolAction(actionType types.ActionType, actionsList []string) bool {
 
 func isDepositAction(actionType types.ActionType) bool {
 	switch actionType {
-	case types.AaveETHDepositAction, types.AaveUSDCSupplyAction, types.MoonwellDepositAction:
+	case handlers.AaveETHDepositAction, handlers.AaveUSDCSupplyAction, handlers.MoonwellDepositAction:
 		return true
 	default:
 		return false
@@ -56,7 +57,7 @@ func isLastActionDeposit(lastActions []string) bool {
 
 func isWithdrawAction(actionType types.ActionType) bool {
 	switch actionType {
-	case types.AaveETHWithdrawAction, types.AaveUSDCWithdrawAction, types.MoonwellWithdrawAction:
+	case handlers.AaveETHWithdrawAction, handlers.AaveUSDCWithdrawAction, handlers.MoonwellWithdrawAction:
 		return true
 	default:
 		return false
@@ -76,11 +77,11 @@ func getPoolActions(poolName string, actionsList []string) []string {
 
 func getPoolName(actionType types.ActionType) string {
 	switch actionType {
-	case types.AaveETHDepositAction, types.AaveETHWithdrawAction:
+	case handlers.AaveETHDepositAction, handlers.AaveETHWithdrawAction:
 		return "AaveETH"
-	case types.AaveUSDCSupplyAction, types.AaveUSDCWithdrawAction:
+	case handlers.AaveUSDCSupplyAction, handlers.AaveUSDCWithdrawAction:
 		return "AaveUSDC"
-	case types.MoonwellDepositAction, types.MoonwellWithdrawAction:
+	case handlers.MoonwellDepositAction, handlers.MoonwellWithdrawAction:
 		return "Moonwell"
 	default:
 		return ""
@@ -90,12 +91,12 @@ func getPoolName(actionType types.ActionType) string {
 func hasCorrespondingDeposit(actionType types.ActionType, actionsList []string) bool {
 	expectedDepositAction := ""
 	switch actionType {
-	case types.AaveETHWithdrawAction:
-		expectedDepositAction = string(types.AaveETHDepositAction)
-	case types.AaveUSDCWithdrawAction:
-		expectedDepositAction = string(types.AaveUSDCSupplyAction)
-	case types.MoonwellWithdrawAction:
-		expectedDepositAction = string(types.MoonwellDepositAction)
+	case handlers.AaveETHWithdrawAction:
+		expectedDepositAction = string(handlers.AaveETHDepositAction)
+	case handlers.AaveUSDCWithdrawAction:
+		expectedDepositAction = string(handlers.AaveUSDCSupplyAction)
+	case handlers.MoonwellWithdrawAction:
+		expectedDepositAction = string(handlers.MoonwellDepositAction)
 	default:
 		return false
 	}
@@ -110,12 +111,12 @@ func hasCorrespondingDeposit(actionType types.ActionType, actionsList []string)
 
 func isValidTokenForLiquidAction(actionType types.ActionType, token common.Address) bool {
 	validTokensForActions := map[types.ActionType]map[common.Address]struct{}{
-		types.AaveETHDepositAction:   {config.WETH: {}},
-		types.AaveUSDCSupplyAction:   {config.USDC: {}},
-		types.AaveUSDCWithdrawAction: {config.USDC: {}},
-		types.AaveETHWithdrawAction:  {config.WETH: {}},
-		types.MoonwellDepositAction:  {config.WETH: {}},
-		types.MoonwellWithdrawAction: {config.WETH: {}},
+		handlers.AaveETHDepositAction:   {config.Chains.Base.WETH: {}},
+		handlers.AaveUSDCSupplyAction:   {config.Chains.Base.USDC: {}},
+		handlers.AaveUSDCWithdrawAction: {config.Chains.Base.USDC: {}},
+		handlers.AaveETHWithdrawAction:  {config.Chains.Base.WETH: {}},
+		handlers.MoonwellDepositAction:  {config.Chains.Base.WETH: {}},
+		handlers.MoonwellWithdrawAction: {config.Chains.Base.WETH: {}},
 	}
 
 	validTokens, exists := validTokensForActions[actionType]
@@ -1,16 +1,17 @@
 package randomization
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
-	"strings"
 	"sync"
 	"time"
 
 	"base/account"
 	"base/actions"
+	"base/actions/handlers"
 	"base/actions/types"
 	"base/config"
 	"base/ethClient"
@@ -36,19 +37,19 @@ func NewRandomizer(availableTokens []common.Address, availableNFTs map[string]ma
 	}
 }
 
-func (r *Randomizer) GenerateActionSequence(modules *account.ModulesConfig, walletConfig *account.WalletConfig, acc *account.Account) ([]actions.Action, error) {
+func (r *Randomizer) GenerateActionSequence(ctx context.Context, modules account.ModulesConfig, walletConfig *account.WalletConfig, acc *account.Account) ([]actions.Action, error) {
 	numActions, err := getNumActions(walletConfig)
 	if err != nil {
 		numActions = 10
 	}
 
-	availableActionTypes := r.getAvailableActions(modules, walletConfig)
+	availableActionTypes := actions.AvailableActions(modules, walletConfig)
 	if len(availableActionTypes) == 0 {
 		return nil, errors.New("no action types available for generation")
 	}
 
-	if len(availableActionTypes) == 1 && availableActionTypes[0] == types.CollectorModAction {
-		action, err := r.GenerateSingleAction(availableActionTypes[0], acc)
+	if len(availableActionTypes) == 1 && availableActionTypes[0] == handlers.CollectorModAction {
+		action, err := r.GenerateSingleAction(ctx, availableActionTypes[0], acc)
 		if err != nil {
 			return nil, err
 		}
@@ -60,7 +61,7 @@ func (r *Randomizer) GenerateActionSequence(modules *account.ModulesConfig, wall
 	for i := 0; i < numActions; i++ {
 		actionType := availableActionTypes[rand.Intn(len(availableActionTypes))]
 
-		if actionType == types.BaseNameAction {
+		if actionType == handlers.BaseNameAction {
 			if baseNameActionAdded {
 				continue
 			}
@@ -72,7 +73,7 @@ func (r *Randomizer) GenerateActionSequence(modules *account.ModulesConfig, wall
 			continue
 		}
 
-		action, err := r.GenerateSingleAction(actionType, acc)
+		action, err := r.GenerateSingleAction(ctx, actionType, acc)
 		if err != nil {
 			continue
 		}
@@ -83,87 +84,11 @@ func (r *Randomizer) GenerateActionSequence(modules *account.ModulesConfig, wall
 
 	return actionsList, nil
 }
-func (r *Randomizer) getAvailableActions(cfg *account.ModulesConfig, wltcfg *account.WalletConfig) []types.ActionType {
-	actionMap := map[types.ActionType]bool{}
-	if cfg.Uniswap {
-		actionMap[types.UniswapAction] = true
-	}
-	if cfg.Pancake {
-		actionMap[types.PancakeAction] = true
-	}
-	if cfg.Woofi {
-		actionMap[types.WoofiAction] = true
-	}
-	if cfg.OpenOcean {
-		actionMap[types.OpenOceanAction] = true
-	}
-	if cfg.Odos {
-		actionMap[types.OdosAction] = true
-	}
-	if cfg.Refuel {
-		actionMap[types.RefuelAction] = true
-	}
-	if cfg.Zora {
-		actionMap[types.ZoraAction] = true
-	}
-	if cfg.NFT2Me {
-		actionMap[types.NFT2MeAction] = true
-	}
-	if cfg.BaseNames && (strings.TrimSpace(wltcfg.BaseName) != "") {
-		actionMap[types.BaseNameAction] = true
-	}
-	if cfg.Stargate {
-		actionMap[types.BridgeAction] = true
-	}
-	if cfg.Dmail {
-		actionMap[types.DmailAction] = true
-	}
-	if cfg.Aave {
-		actionMap[types.AaveETHDepositAction] = true
-		actionMap[types.AaveETHWithdrawAction] = true
-		actionMap[types.AaveUSDCSupplyAction] = true
-		actionMap[types.AaveUSDCWithdrawAction] = true
-	}
-	if cfg.Moonwell {
-		actionMap[types.MoonwellDepositAction] = true
-		actionMap[types.MoonwellWithdrawAction] = true
-	}
-	if cfg.Collector {
-		actionMap[types.CollectorModAction] = true
-	}
-
-	availableActionTypes := make([]types.ActionType, 0, len(actionMap))
-	for action := range actionMap {
-		availableActionTypes = append(availableActionTypes, action)
-	}
-	return availableActionTypes
-}
-func (r *Randomizer) GenerateSingleAction(actionType types.ActionType, acc *account.Account) (actions.Action, error) {
-	switch actionType {
-	case types.UniswapAction, types.PancakeAction, types.WoofiAction, types.OdosAction, types.OpenOceanAction:
-		return r.generateSwapAction(actionType, acc)
-	case types.ZoraAction, types.NFT2MeAction:
-		return r.generateNFTAction(actionType)
-	case types.AaveETHDepositAction, types.AaveETHWithdrawAction, types.AaveUSDCSupplyAction,
-		types.AaveUSDCWithdrawAction, types.MoonwellDepositAction, types.MoonwellWithdrawAction:
-		return r.generatePoolAction(actionType, acc)
-	case types.RefuelAction:
-		return r.generateRefuelActions(actionType, acc)
-	case types.BridgeAction, types.DmailAction, types.CollectorModAction:
-		return actions.Action{Type: actionType}, nil
-	case types.BaseNameAction:
-		return actions.Action{
-			Type: actionType,
-			BSNParams: types.BSNParams{
-				Name: acc.BaseName,
-			},
-		}, nil
-	default:
-		return actions.Action{}, errors.New("неизвестный тип действия")
-	}
+func (r *Randomizer) GenerateSingleAction(ctx context.Context, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+	return actions.NewAction(ctx, r, actionType, acc)
 }
 
-func (r *Randomizer) generateNFTAction(actionType types.ActionType) (actions.Action, error) {
+func (r *Randomizer) NFTAction(actionType types.ActionType) (actions.Action, error) {
 	r.nftMutex.Lock()
 	defer r.nftMutex.Unlock()
 
@@ -184,7 +109,7 @@ func (r *Randomizer) generateNFTAction(actionType types.ActionType) (actions.Act
 	}, nil
 }
 
-func (r *Randomizer) generateRefuelActions(actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+func (r *Randomizer) RefuelAction(actionType types.ActionType, acc *account.Account) (actions.Action, error) {
 	availableChains := []string{"arbitrum", "optimism", "polygon", "avalanche"}
 
 	var dstChain string
@@ -206,13 +131,13 @@ func (r *Randomizer) generateRefuelActions(actionType types.ActionType, acc *acc
 	}, nil
 }
 
-func (r *Randomizer) generateSwapAction(actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+func (r *Randomizer) SwapAction(ctx context.Context, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
 	r.tokenMutex.Lock()
 	defer r.tokenMutex.Unlock()
 
 	filteredTokens := r.filtredTokenForDex(actionType)
 
-	fromToken, err := r.selectFromToken(actionType, acc, filteredTokens)
+	fromToken, err := r.selectFromToken(ctx, actionType, acc, filteredTokens)
 	if err != nil {
 		return actions.Action{}, err
 	}
@@ -231,7 +156,7 @@ func (r *Randomizer) generateSwapAction(actionType types.ActionType, acc *accoun
 	}, nil
 }
 
-func (r *Randomizer) generatePoolAction(actionType types.ActionType, acc *account.Account) (actions.Action, error) {
+func (r *Randomizer) PoolAction(ctx context.Context, actionType types.ActionType, acc *account.Account) (actions.Action, error) {
 	lastActionIsDeposit := isLastActionDeposit(acc.LastPoolAction)
 
 	if (isDepositAction(actionType) && lastActionIsDeposit) ||
@@ -239,7 +164,7 @@ func (r *Randomizer) generatePoolAction(actionType types.ActionType, acc *accoun
 		return actions.Action{}, fmt.Errorf("action %v out of sequence deposit-withdraw", string(actionType))
 	}
 
-	selectedToken, err := r.findEligibleToken(actionType, acc)
+	selectedToken, err := r.findEligibleToken(ctx, actionType, acc)
 	if err != nil {
 		return actions.Action{}, err
 	}
@@ -262,7 +187,7 @@ func (r *Randomizer) generatePoolAction(actionType types.ActionType, acc *accoun
 func (r *Randomizer) filtredTokenForDex(actionType types.ActionType) []common.Address {
 	filtred := []common.Address{}
 	for _, token := range r.availableTokens {
-		if actionType == types.WoofiAction && token == config.USDbC {
+		if actionType == handlers.WoofiAction && token == config.Chains.Base.USDbC {
 			continue
 		}
 		filtred = append(filtred, token)
@@ -270,13 +195,13 @@ func (r *Randomizer) filtredTokenForDex(actionType types.ActionType) []common.Ad
 	return filtred
 }
 
-func (r *Randomizer) selectFromToken(actionType types.ActionType, acc *account.Account, filtredTokens []common.Address) (common.Address, error) {
+func (r *Randomizer) selectFromToken(ctx context.Context, actionType types.ActionType, acc *account.Account, filtredTokens []common.Address) (common.Address, error) {
 	if len(acc.LastSwaps) == 0 {
-		return r.selectTokenWithHighestBalance(acc, filtredTokens)
+		return r.selectTokenWithHighestBalance(ctx, acc, filtredTokens)
 	}
 
 	fromToken := acc.LastSwaps[len(acc.LastSwaps)-1].To
-	if actionType == types.WoofiAction && fromToken == config.USDbC {
+	if actionType == handlers.WoofiAction && fromToken == config.Chains.Base.USDbC {
 		return common.Address{}, errors.New("woofi don't support usdbc")
 	}
 
@@ -309,7 +234,7 @@ func (r *Randomizer) selectToToken(acc *account.Account, fromToken common.Addres
 	return common.Address{}, errors.New("failed generate 'to token' for swap")
 }
 
-func (r *Randomizer) selectTokenWithHighestBalance(acc *account.Account, filtredTokens []common.Address) (common.Address, error) {
+func (r *Randomizer) selectTokenWithHighestBalance(ctx context.Context, acc *account.Account, filtredTokens []common.Address) (common.Address, error) {
 	var selectedToken common.Address
 	highestBalance := big.NewFloat(0)
 
@@ -318,14 +243,19 @@ func (r *Randomizer) selectTokenWithHighestBalance(acc *account.Account, filtred
 		return common.Address{}, errors.New("haven't client for base chain")
 	}
 
+	balances, err := baseClient.BatchBalances(ctx, acc.Address, filtredTokens)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed check balances: %v", err)
+	}
+
 	for _, token := range filtredTokens {
-		balance, err := baseClient.BalanceCheck(acc.Address, token)
-		if err != nil {
-			logger.GlobalLogger.Warn("failed check balance for: %s %v", token, err)
+		balance, ok := balances[token]
+		if !ok {
+			logger.GlobalLogger.Warnf("failed check balance for: %s", token)
 			continue
 		}
 
-		normilizeBalance, err := baseClient.NormalizeBalance(balance, token)
+		normilizeBalance, err := baseClient.NormalizeBalance(ctx, balance, token)
 		if err != nil {
 			logger.GlobalLogger.Warn("failed convert to $ balance for: %s %v", token, err)
 			continue
@@ -344,7 +274,7 @@ func (r *Randomizer) selectTokenWithHighestBalance(acc *account.Account, filtred
 	return selectedToken, nil
 }
 
-func (r *Randomizer) findEligibleToken(actionType types.ActionType, acc *account.Account) (common.Address, error) {
+func (r *Randomizer) findEligibleToken(ctx context.Context, actionType types.ActionType, acc *account.Account) (common.Address, error) {
 	baseClient, exists := r.Clients["base"]
 	if !exists {
 		return common.Address{}, errors.New("haven't client for base chain")
@@ -352,9 +282,14 @@ func (r *Randomizer) findEligibleToken(actionType types.ActionType, acc *account
 	highestBalance := big.NewInt(0)
 	var selectedToken common.Address
 
+	balances, err := baseClient.BatchBalances(ctx, acc.Address, r.availableTokens)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed check balances: %v", err)
+	}
+
 	for _, token := range r.availableTokens {
-		balance, err := baseClient.BalanceCheck(acc.Address, token)
-		if err != nil {
+		balance, ok := balances[token]
+		if !ok {
 			continue
 		}
 
@@ -0,0 +1,133 @@
+package actions
+
+import (
+	"base/account"
+	"base/actions/types"
+	"context"
+	"fmt"
+)
+
+// ParamSource generates the random parameters that modules need for their actions. It is
+// implemented by the randomizer, which owns the token and NFT pools.
+type ParamSource interface {
+	SwapAction(ctx context.Context, actionType types.ActionType, acc *account.Account) (Action, error)
+	NFTAction(actionType types.ActionType) (Action, error)
+	PoolAction(ctx context.Context, actionType types.ActionType, acc *account.Account) (Action, error)
+	RefuelAction(actionType types.ActionType, acc *account.Account) (Action, error)
+}
+
+// Module describes everything the randomizer and the executor need to know about a protocol:
+// the action types it adds, the flag that enables it in the account config, how parameters are
+// generated and which handler runs the action.
+type Module struct {
+	Name      string
+	ConfigKey string
+	Actions   []types.ActionType
+	// Params generates a new action of the given type, nil means the action needs no params.
+	Params func(ctx context.Context, src ParamSource, actionType types.ActionType, acc *account.Account) (Action, error)
+	// Available reports whether the wallet can use the module at all, nil means always.
+	Available func(wallet *account.WalletConfig) bool
+	// ParamsOf points at the params field the module's actions use, so that only it is stored.
+	// nil means the actions carry no params.
+	ParamsOf func(action *Action) any
+	Handler  func(action Action) ActionHandler
+}
+
+var (
+	registry      []*Module
+	moduleByKey   = make(map[string]*Module)
+	moduleByTypes = make(map[types.ActionType]*Module)
+)
+
+// Register adds a module to the registry, handlers call it from init() in the file that
+// implements them. It panics on duplicate config keys or action types,
+// since that can only be a programming error.
+func Register(m Module) {
+	if m.Name == "" || m.ConfigKey == "" || len(m.Actions) == 0 || m.Handler == nil {
+		panic(fmt.Sprintf("invalid module registration: %+v", m))
+	}
+	if _, ok := moduleByKey[m.ConfigKey]; ok {
+		panic("module already registered: " + m.ConfigKey)
+	}
+
+	module := &m
+	for _, actionType := range m.Actions {
+		if _, ok := moduleByTypes[actionType]; ok {
+			panic("action type already registered: " + string(actionType))
+		}
+		moduleByTypes[actionType] = module
+	}
+
+	moduleByKey[m.ConfigKey] = module
+	registry = append(registry, module)
+}
+
+// Modules returns all registered modules in registration order.
+func Modules() []*Module {
+	return append([]*Module(nil), registry...)
+}
+
+func ModuleByKey(key string) (*Module, bool) {
+	m, ok := moduleByKey[key]
+	return m, ok
+}
+
+func ModuleFor(actionType types.ActionType) (*Module, bool) {
+	m, ok := moduleByTypes[actionType]
+	return m, ok
+}
+
+// ModuleName returns the name of the module the action belongs to, used to label journal records.
+func ModuleName(actionType types.ActionType) string {
+	if m, ok := moduleByTypes[actionType]; ok {
+		return m.Name
+	}
+	return string(actionType)
+}
+
+// AvailableActions lists the action types of every module enabled in the config and usable by
+// the wallet.
+func AvailableActions(cfg account.ModulesConfig, wallet *account.WalletConfig) []types.ActionType {
+	var available []types.ActionType
+	for _, m := range registry {
+		if !cfg.Enabled(m.ConfigKey) {
+			continue
+		}
+		if m.Available != nil && !m.Available(wallet) {
+			continue
+		}
+		available = append(available, m.Actions...)
+	}
+	return available
+}
+
+// UnknownModules returns the config keys that no registered module answers to.
+func UnknownModules(cfg account.ModulesConfig) []string {
+	var unknown []string
+	for key := range cfg {
+		if _, ok := moduleByKey[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown
+}
+
+// NewAction generates an action of the given type through its module.
+func NewAction(ctx context.Context, src ParamSource, actionType types.ActionType, acc *account.Account) (Action, error) {
+	m, ok := moduleByTypes[actionType]
+	if !ok {
+		return Action{}, fmt.Errorf("неизвестный тип действия: %s", actionType)
+	}
+	if m.Params == nil {
+		return Action{Type: actionType}, nil
+	}
+	return m.Params(ctx, src, actionType, acc)
+}
+
+func GetActionHandler(action Action) (ActionHandler, error) {
+	m, ok := moduleByTypes[action.Type]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный тип действия: %s", action.Type)
+	}
+	return m.Handler(action), nil
+}
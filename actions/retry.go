@@ -0,0 +1,262 @@
+package actions
+
+import (
+	"base/account"
+	"base/actions/types"
+	"base/config"
+	"base/ethClient"
+	"base/logger"
+	"base/modules"
+	"base/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ErrorClass string
+
+const (
+	ErrorTransient         ErrorClass = "transient"
+	ErrorRevert            ErrorClass = "revert"
+	ErrorInsufficientFunds ErrorClass = "insufficient_funds"
+	ErrorQuoteExpired      ErrorClass = "quote_expired"
+	ErrorSkipped           ErrorClass = "skipped"
+	ErrorUnknown           ErrorClass = "unknown"
+)
+
+var (
+	insufficientFundsMarkers = []string{"insufficient funds", "insufficient balance", "exceeds balance"}
+	quoteExpiredMarkers      = []string{
+		"too little received", "insufficient_output_amount", "return amount is not enough",
+		"slippage", "quote expired", "expired quote", "minimum output amount is zero",
+	}
+	revertMarkers    = []string{"execution reverted", "transaction failed", "reverted"}
+	transientMarkers = []string{
+		"timeout", "timed out", "connection reset", "connection refused", "broken pipe",
+		"too many requests", "rate limit", "all rpc endpoints", "header not found",
+		"was cancelled", "no such host", "tls handshake",
+	}
+	httpStatusRe = regexp.MustCompile(`status code: (\d{3})`)
+	// errors flattened with %v lose io.EOF, but keep it as a separate word
+	eofRe = regexp.MustCompile(`\b(unexpected )?eof\b`)
+)
+
+var ErrRefuelFailed = errors.New("refuel failed")
+
+// ClassifyError sorts an action error into a class the retry policy can act on. Checks go from
+// the most specific marker to the most generic one, so a revert caused by slippage counts as an
+// expired quote rather than a plain revert.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, ErrSkipped) {
+		return ErrorSkipped
+	}
+
+	// a broadcast transaction may still land, retrying would risk doing the action twice
+	if errors.Is(err, ethClient.ErrBroadcastUnconfirmed) {
+		return ErrorUnknown
+	}
+
+	msg := strings.ToLower(err.Error())
+
+	switch {
+	case containsAny(msg, insufficientFundsMarkers):
+		return ErrorInsufficientFunds
+	case containsAny(msg, quoteExpiredMarkers):
+		return ErrorQuoteExpired
+	case containsAny(msg, revertMarkers):
+		return ErrorRevert
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTransient
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || eofRe.MatchString(msg) {
+		return ErrorTransient
+	}
+	if match := httpStatusRe.FindStringSubmatch(msg); match != nil {
+		if match[1] == "429" || match[1][0] == '5' {
+			return ErrorTransient
+		}
+		return ErrorUnknown
+	}
+	if containsAny(msg, transientMarkers) {
+		return ErrorTransient
+	}
+
+	return ErrorUnknown
+}
+
+func containsAny(msg string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	RetryOn        map[ErrorClass]bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     2 * time.Minute,
+		RetryOn: map[ErrorClass]bool{
+			ErrorTransient:    true,
+			ErrorQuoteExpired: true,
+		},
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff << (attempt - 1)
+	if delay > p.MaxBackoff || delay <= 0 {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// RetryPolicies holds the "default" policy and per action type overrides from the config.
+type RetryPolicies struct {
+	Default  RetryPolicy
+	ByAction map[types.ActionType]RetryPolicy
+}
+
+func (p RetryPolicies) For(actionType types.ActionType) RetryPolicy {
+	if policy, ok := p.ByAction[actionType]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+func NewRetryPolicies(cfg map[string]config.RetryConfig) (RetryPolicies, error) {
+	policies := RetryPolicies{
+		Default:  DefaultRetryPolicy(),
+		ByAction: make(map[types.ActionType]RetryPolicy),
+	}
+
+	if defaultCfg, ok := cfg["default"]; ok {
+		policy, err := newRetryPolicy(policies.Default, defaultCfg)
+		if err != nil {
+			return policies, fmt.Errorf("retry.default: %v", err)
+		}
+		policies.Default = policy
+	}
+
+	for name, actionCfg := range cfg {
+		if name == "default" {
+			continue
+		}
+		if _, ok := ModuleFor(types.ActionType(name)); !ok {
+			return policies, fmt.Errorf("retry.%s: unknown action type, valid keys: %s", name, strings.Join(retryKeys(), ", "))
+		}
+		policy, err := newRetryPolicy(policies.Default, actionCfg)
+		if err != nil {
+			return policies, fmt.Errorf("retry.%s: %v", name, err)
+		}
+		policies.ByAction[types.ActionType(name)] = policy
+	}
+
+	return policies, nil
+}
+
+// retryKeys lists the keys accepted in the retry config: "default" and every registered action type.
+func retryKeys() []string {
+	keys := []string{"default"}
+	for _, m := range registry {
+		for _, actionType := range m.Actions {
+			keys = append(keys, string(actionType))
+		}
+	}
+	sort.Strings(keys[1:])
+	return keys
+}
+
+func newRetryPolicy(base RetryPolicy, cfg config.RetryConfig) (RetryPolicy, error) {
+	policy := base
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.BackoffSec > 0 {
+		policy.InitialBackoff = time.Duration(cfg.BackoffSec * float64(time.Second))
+	}
+	if cfg.MaxBackoffSec > 0 {
+		policy.MaxBackoff = time.Duration(cfg.MaxBackoffSec * float64(time.Second))
+	}
+	if cfg.RetryOn != nil {
+		policy.RetryOn = make(map[ErrorClass]bool, len(cfg.RetryOn))
+		for _, class := range cfg.RetryOn {
+			switch c := ErrorClass(class); c {
+			case ErrorTransient, ErrorRevert, ErrorInsufficientFunds, ErrorQuoteExpired:
+				policy.RetryOn[c] = true
+			default:
+				return policy, fmt.Errorf("unknown error class %q", class)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// TakeActionsWithRetry runs the action until it succeeds or the policy gives up. Every attempt
+// goes through the handler again, so DEX quotes and aggregator routes are fetched anew. On
+// insufficient funds, refuel is called once before the next attempt when it is set. No new
+// attempt is started once ctx is done.
+func (a Action) TakeActionsWithRetry(ctx context.Context, mods modules.Modules, acc *account.Account, client *ethClient.Client, config *config.Config, policy RetryPolicy, refuel func() error) (int, error) {
+	attempts := 0
+	refueled := false
+
+	for {
+		attempts++
+		err := a.TakeActions(ctx, mods, acc, a, client, config)
+		if err == nil {
+			return attempts, nil
+		}
+		if ctx.Err() != nil {
+			return attempts, err
+		}
+
+		if errors.Is(err, ethClient.ErrBroadcastUnconfirmed) {
+			return attempts, err
+		}
+
+		class := ClassifyError(err)
+		if class == ErrorInsufficientFunds && refuel != nil && !refueled {
+			refueled = true
+			if refuelErr := refuel(); refuelErr != nil {
+				return attempts, fmt.Errorf("%v: %w: %v", err, ErrRefuelFailed, refuelErr)
+			}
+			if attempts < policy.MaxAttempts {
+				continue
+			}
+		}
+
+		if !policy.RetryOn[class] || attempts >= policy.MaxAttempts {
+			return attempts, err
+		}
+
+		delay := policy.backoff(attempts)
+		if client.DryRun {
+			delay = 0
+		}
+		logger.GlobalLogger.Warnf("Аккаунт %d: ошибка %s (%s), попытка %d/%d через %v: %v", acc.AccountID, a.Type, class, attempts+1, policy.MaxAttempts, delay, err)
+		if err := utils.Sleep(ctx, delay); err != nil {
+			return attempts, err
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package actions
+
+import (
+	"base/account"
+	"base/actions/types"
+	"base/config"
+	"base/ethClient"
+	"base/modules"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+const retryTestAction types.ActionType = "retry_test"
+
+// scriptedHandler fails with errs in order, then succeeds.
+type scriptedHandler struct {
+	errs  []error
+	calls int
+}
+
+func (h *scriptedHandler) Execute(ctx context.Context, acc *account.Account, mods modules.Modules, client *ethClient.Client, config *config.Config) error {
+	h.calls++
+	if h.calls <= len(h.errs) {
+		return h.errs[h.calls-1]
+	}
+	return nil
+}
+
+var script *scriptedHandler
+
+func init() {
+	Register(Module{
+		Name:      string(retryTestAction),
+		ConfigKey: string(retryTestAction),
+		Actions:   []types.ActionType{retryTestAction},
+		Handler: func(action Action) ActionHandler {
+			return script
+		},
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{nil, ""},
+		{fmt.Errorf("invalid amount to swap: %w", ErrSkipped), ErrorSkipped},
+		{errors.New("insufficient funds for gas * price + value"), ErrorInsufficientFunds},
+		{errors.New("execution reverted: Too little received"), ErrorQuoteExpired},
+		{errors.New("execution reverted"), ErrorRevert},
+		{fmt.Errorf("%w: execution reverted", ethClient.ErrBroadcastUnconfirmed), ErrorUnknown},
+		{fmt.Errorf("%w: transaction wait timeout", ethClient.ErrBroadcastUnconfirmed), ErrorUnknown},
+		{fmt.Errorf("quote: %w", context.DeadlineExceeded), ErrorTransient},
+		{&net.OpError{Op: "dial", Err: errors.New("refused")}, ErrorTransient},
+		{fmt.Errorf("read: %w", io.EOF), ErrorTransient},
+		{fmt.Errorf("read: %w", io.ErrUnexpectedEOF), ErrorTransient},
+		{errors.New(`failed to estimate gas: Post "https://mainnet.base.org": EOF`), ErrorTransient},
+		{errors.New("the token thereof is not supported"), ErrorUnknown},
+		{errors.New("request failed, status code: 429"), ErrorTransient},
+		{errors.New("request failed, status code: 503"), ErrorTransient},
+		{errors.New("request failed, status code: 400"), ErrorUnknown},
+		{errors.New("all RPC endpoints for base failed: timeout"), ErrorTransient},
+		{errors.New("something else"), ErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTakeActionsWithRetry(t *testing.T) {
+	transient := errors.New("connection reset by peer")
+	revert := errors.New("execution reverted")
+	funds := errors.New("insufficient funds for gas * price + value")
+	broadcast := fmt.Errorf("%w: transaction wait timeout", ethClient.ErrBroadcastUnconfirmed)
+
+	everything := DefaultRetryPolicy()
+	everything.RetryOn = map[ErrorClass]bool{ErrorTransient: true, ErrorRevert: true, ErrorUnknown: true}
+
+	tests := []struct {
+		name         string
+		errs         []error
+		policy       RetryPolicy
+		refuelErr    error
+		wantAttempts int
+		wantErr      error
+		wantRefuels  int
+	}{
+		{name: "success", policy: DefaultRetryPolicy(), wantAttempts: 1},
+		{name: "transient then success", errs: []error{transient, transient}, policy: DefaultRetryPolicy(), wantAttempts: 3},
+		{name: "gives up after max attempts", errs: []error{transient, transient, transient, transient}, policy: DefaultRetryPolicy(), wantAttempts: 3, wantErr: transient},
+		{name: "revert is not retried by default", errs: []error{revert}, policy: DefaultRetryPolicy(), wantAttempts: 1, wantErr: revert},
+		{name: "broadcast is never retried", errs: []error{broadcast}, policy: everything, wantAttempts: 1, wantErr: ethClient.ErrBroadcastUnconfirmed},
+		{name: "refuel once then success", errs: []error{funds}, policy: DefaultRetryPolicy(), wantAttempts: 2, wantRefuels: 1},
+		{name: "refuel only once", errs: []error{funds, funds}, policy: DefaultRetryPolicy(), wantAttempts: 2, wantErr: funds, wantRefuels: 1},
+		{name: "failed refuel stops", errs: []error{funds}, policy: DefaultRetryPolicy(), refuelErr: errors.New("no native balance"), wantAttempts: 1, wantErr: ErrRefuelFailed, wantRefuels: 1},
+	}
+
+	acc := &account.Account{AccountID: 1}
+	client := &ethClient.Client{DryRun: true} // no backoff delays
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script = &scriptedHandler{errs: tt.errs}
+			refuels := 0
+			refuel := func() error {
+				refuels++
+				return tt.refuelErr
+			}
+
+			action := Action{Type: retryTestAction}
+			attempts, err := action.TakeActionsWithRetry(context.Background(), modules.Modules{}, acc, client, nil, tt.policy, refuel)
+			if attempts != tt.wantAttempts || script.calls != tt.wantAttempts {
+				t.Errorf("attempts = %d, handler calls = %d, want %d", attempts, script.calls, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if refuels != tt.wantRefuels {
+				t.Errorf("refuels = %d, want %d", refuels, tt.wantRefuels)
+			}
+		})
+	}
+}
+
+func TestTakeActionsWithRetryStopsWithContext(t *testing.T) {
+	script = &scriptedHandler{errs: []error{errors.New("timeout")}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	action := Action{Type: retryTestAction}
+	attempts, err := action.TakeActionsWithRetry(ctx, modules.Modules{}, &account.Account{}, &ethClient.Client{}, nil, DefaultRetryPolicy(), nil)
+	if attempts != 1 || err == nil {
+		t.Fatalf("attempts = %d, err = %v, want one failed attempt", attempts, err)
+	}
+}
+
+func TestNewRetryPolicies(t *testing.T) {
+	policies, err := NewRetryPolicies(map[string]config.RetryConfig{
+		"default":    {MaxAttempts: 5},
+		"retry_test": {MaxAttempts: 2, RetryOn: []string{"revert"}},
+	})
+	if err != nil {
+		t.Fatalf("NewRetryPolicies: %v", err)
+	}
+	if got := policies.For("other").MaxAttempts; got != 5 {
+		t.Errorf("default max attempts = %d, want 5", got)
+	}
+	if got := policies.For(retryTestAction); got.MaxAttempts != 2 || !got.RetryOn[ErrorRevert] || got.RetryOn[ErrorTransient] {
+		t.Errorf("retry_test policy = %+v", got)
+	}
+
+	_, err = NewRetryPolicies(map[string]config.RetryConfig{"swapp": {MaxAttempts: 2}})
+	if err == nil || !strings.Contains(err.Error(), "retry.swapp") || !strings.Contains(err.Error(), "retry_test") {
+		t.Errorf("unknown key error = %v, want the key and the valid keys", err)
+	}
+
+	if _, err := NewRetryPolicies(map[string]config.RetryConfig{"default": {RetryOn: []string{"sometimes"}}}); err == nil {
+		t.Error("unknown error class accepted")
+	}
+}
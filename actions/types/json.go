@@ -0,0 +1,132 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Params are encoded with camelCase keys. encoding/json matches keys case-insensitively, so the
+// keys also decode the Go field names that older state files were written with.
+
+// Amount encodes a big integer as a decimal string. It decodes both strings and the bare
+// numbers older state files contain.
+type Amount big.Int
+
+func (a *Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*big.Int)(a).String())
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if _, ok := (*big.Int)(a).SetString(string(data), 10); !ok {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	return nil
+}
+
+func (p LiquidParams) MarshalJSON() ([]byte, error) {
+	type alias LiquidParams
+	return json.Marshal(struct {
+		alias
+		Amount *Amount `json:"amount,omitempty"`
+	}{alias(p), (*Amount)(p.Amount)})
+}
+
+func (p *LiquidParams) UnmarshalJSON(data []byte) error {
+	type alias LiquidParams
+	aux := struct {
+		*alias
+		Amount *Amount `json:"amount"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Amount = (*big.Int)(aux.Amount)
+	return nil
+}
+
+func (p DexParams) MarshalJSON() ([]byte, error) {
+	type alias DexParams
+	return json.Marshal(struct {
+		alias
+		AmountToSwap *Amount `json:"amountToSwap,omitempty"`
+	}{alias(p), (*Amount)(p.AmountToSwap)})
+}
+
+func (p *DexParams) UnmarshalJSON(data []byte) error {
+	type alias DexParams
+	aux := struct {
+		*alias
+		AmountToSwap *Amount `json:"amountToSwap"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.AmountToSwap = (*big.Int)(aux.AmountToSwap)
+	return nil
+}
+
+func (p BridgeParams) MarshalJSON() ([]byte, error) {
+	type alias BridgeParams
+	return json.Marshal(struct {
+		alias
+		AmountToBridge *Amount `json:"amountToBridge,omitempty"`
+	}{alias(p), (*Amount)(p.AmountToBridge)})
+}
+
+func (p *BridgeParams) UnmarshalJSON(data []byte) error {
+	type alias BridgeParams
+	aux := struct {
+		*alias
+		AmountToBridge *Amount `json:"amountToBridge"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.AmountToBridge = (*big.Int)(aux.AmountToBridge)
+	return nil
+}
+
+func (p RefuelParams) MarshalJSON() ([]byte, error) {
+	type alias RefuelParams
+	return json.Marshal(struct {
+		alias
+		AmountToBridge *Amount `json:"amountToBridge,omitempty"`
+	}{alias(p), (*Amount)(p.AmountToBridge)})
+}
+
+func (p *RefuelParams) UnmarshalJSON(data []byte) error {
+	type alias RefuelParams
+	aux := struct {
+		*alias
+		AmountToBridge *Amount `json:"amountToBridge"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.AmountToBridge = (*big.Int)(aux.AmountToBridge)
+	return nil
+}
+
+func (p NftMintParams) MarshalJSON() ([]byte, error) {
+	type alias NftMintParams
+	return json.Marshal(struct {
+		alias
+		Price *Amount `json:"price,omitempty"`
+	}{alias(p), (*Amount)(p.Price)})
+}
+
+func (p *NftMintParams) UnmarshalJSON(data []byte) error {
+	type alias NftMintParams
+	aux := struct {
+		*alias
+		Price *Amount `json:"price"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Price = (*big.Int)(aux.Price)
+	return nil
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// aboveFloat is 2^53 + 1, the first integer a float64 can not hold.
+var aboveFloat = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 53), big.NewInt(1))
+
+func TestAmountRoundTrip(t *testing.T) {
+	wei, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	for _, amount := range []*big.Int{big.NewInt(0), aboveFloat, wei} {
+		data, err := json.Marshal(DexParams{AmountToSwap: amount})
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if !strings.Contains(string(data), `"amountToSwap":"`+amount.String()+`"`) {
+			t.Errorf("amount not encoded as a decimal string: %s", data)
+		}
+
+		var decoded DexParams
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if decoded.AmountToSwap == nil || decoded.AmountToSwap.Cmp(amount) != 0 {
+			t.Errorf("amount = %v, want %v", decoded.AmountToSwap, amount)
+		}
+	}
+}
+
+func TestAmountDecodesBareNumbers(t *testing.T) {
+	// older state files stored amounts as JSON numbers
+	var decoded BridgeParams
+	if err := json.Unmarshal([]byte(`{"AmountToBridge": 9007199254740993}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.AmountToBridge == nil || decoded.AmountToBridge.Cmp(aboveFloat) != 0 {
+		t.Errorf("amount = %v, want %v", decoded.AmountToBridge, aboveFloat)
+	}
+}
+
+func TestAmountNilAndInvalid(t *testing.T) {
+	data, err := json.Marshal(NftMintParams{MintCA: common.HexToAddress("0x01")})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "price") {
+		t.Errorf("nil amount encoded: %s", data)
+	}
+
+	var decoded NftMintParams
+	if err := json.Unmarshal([]byte(`{"price": null}`), &decoded); err != nil || decoded.Price != nil {
+		t.Errorf("null amount: price = %v, err = %v", decoded.Price, err)
+	}
+	if err := json.Unmarshal([]byte(`{"price": "12abc"}`), &decoded); err == nil {
+		t.Error("invalid amount accepted")
+	}
+}
+
+func TestParamsRoundTrip(t *testing.T) {
+	token := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+
+	liquid := LiquidParams{Type: "aave_supply", Token: token, Amount: aboveFloat}
+	var liquidOut LiquidParams
+	roundTrip(t, liquid, &liquidOut)
+	if liquidOut.Type != liquid.Type || liquidOut.Token != token || liquidOut.Amount.Cmp(aboveFloat) != 0 {
+		t.Errorf("LiquidParams = %+v, want %+v", liquidOut, liquid)
+	}
+
+	refuel := RefuelParams{DstChain: "base", ScrChain: "arbitrum", AmountToBridge: aboveFloat}
+	var refuelOut RefuelParams
+	roundTrip(t, refuel, &refuelOut)
+	if refuelOut.DstChain != refuel.DstChain || refuelOut.ScrChain != refuel.ScrChain || refuelOut.AmountToBridge.Cmp(aboveFloat) != 0 {
+		t.Errorf("RefuelParams = %+v, want %+v", refuelOut, refuel)
+	}
+}
+
+func roundTrip(t *testing.T, in any, out any) {
+	t.Helper()
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+}
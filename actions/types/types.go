@@ -9,55 +9,34 @@ import (
 type ActionType string
 
 type LiquidParams struct {
-	Type   string
-	Token  common.Address
-	Amount *big.Int
+	Type   string         `json:"type"`
+	Token  common.Address `json:"token"`
+	Amount *big.Int       `json:"amount,omitempty"`
 }
 
 type BSNParams struct {
-	Name string
+	Name string `json:"name"`
 }
 
 type DexParams struct {
-	FromToken    common.Address
-	ToToken      common.Address
-	AmountToSwap *big.Int
+	FromToken    common.Address `json:"fromToken"`
+	ToToken      common.Address `json:"toToken"`
+	AmountToSwap *big.Int       `json:"amountToSwap,omitempty"`
 }
 type BridgeParams struct {
-	FromChain      string
-	DstChain       string
-	Token          string
-	AmountToBridge *big.Int
+	FromChain      string   `json:"fromChain"`
+	DstChain       string   `json:"dstChain"`
+	Token          string   `json:"token"`
+	AmountToBridge *big.Int `json:"amountToBridge,omitempty"`
 }
 
 type RefuelParams struct {
-	DstChain       string
-	ScrChain       string
-	AmountToBridge *big.Int
+	DstChain       string   `json:"dstChain"`
+	ScrChain       string   `json:"scrChain"`
+	AmountToBridge *big.Int `json:"amountToBridge,omitempty"`
 }
 
 type NftMintParams struct {
-	MintCA common.Address
-	Price  *big.Int
+	MintCA common.Address `json:"mintCA"`
+	Price  *big.Int       `json:"price,omitempty"`
 }
-
-const (
-	BridgeAction           ActionType = "stargate"
-	UniswapAction          ActionType = "uniswap"
-	PancakeAction          ActionType = "pancake"
-	WoofiAction            ActionType = "woofi"
-	OdosAction             ActionType = "odos"
-	OpenOceanAction        ActionType = "openocean"
-	ZoraAction             ActionType = "zora"
-	NFT2MeAction           ActionType = "nft2me"
-	BaseNameAction         ActionType = "basenames"
-	DmailAction            ActionType = "dmail"
-	RefuelAction           ActionType = "refuel"
-	AaveETHDepositAction   ActionType = "aave_deposit"
-	AaveETHWithdrawAction  ActionType = "aave_withdraw"
-	AaveUSDCSupplyAction   ActionType = "aave_supply"
-	AaveUSDCWithdrawAction ActionType = "aave_withdraw_usdc"
-	MoonwellDepositAction  ActionType = "moonwell_deposit"
-	MoonwellWithdrawAction ActionType = "moonwell_withdraw"
-	CollectorModAction     ActionType = "collector_mod"
-)
@@ -0,0 +1,202 @@
+package analyzer
+
+import (
+	"base/account"
+	"base/config"
+	"base/ethClient"
+	"base/logger"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type WalletStats struct {
+	AccountID       int
+	Address         common.Address
+	TxCount         uint64 // nonce on Base, includes transactions sent outside of this software
+	JournalTxs      int
+	FailedTxs       int
+	UniqueContracts int
+	ActiveDays      int
+	ActiveWeeks     int
+	ActiveMonths    int
+	VolumeUSD       float64
+	GasETH          *big.Float
+	GasUSD          float64
+	BalanceETH      *big.Float
+	LastActivity    time.Time
+}
+
+// Analyze builds per-wallet statistics from the node (nonce, balance) and from the Base
+// records of the transaction journal (contracts, activity, volume, gas).
+func Analyze(accounts []*account.Account, client *ethClient.Client, journalPath string) ([]WalletStats, error) {
+	records, err := ethClient.ReadJournal(journalPath, func(r ethClient.JournalRecord) bool {
+		return r.Chain == client.Chain
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %v", err)
+	}
+
+	byAddress := make(map[common.Address][]ethClient.JournalRecord)
+	for _, record := range records {
+		addr := common.HexToAddress(record.Address)
+		byAddress[addr] = append(byAddress[addr], record)
+	}
+
+	queries := make([]ethClient.TokenQuery, len(accounts))
+	for i, acc := range accounts {
+		queries[i] = ethClient.TokenQuery{Owner: acc.Address, Token: config.Chains.Base.WETH}
+	}
+	balances, err := client.BatchTokenData(context.Background(), queries)
+	if err != nil {
+		logger.GlobalLogger.Warnf("Failed to read balances for the report: %v", err)
+		balances = make([]ethClient.TokenData, len(accounts))
+	}
+
+	ethPrice, err := client.Prices.Price(context.Background(), client, config.Chains.Base.WETH)
+	if err != nil {
+		logger.GlobalLogger.Warnf("Failed to get ETH price for the report: %v", err)
+		ethPrice = new(big.Float)
+	}
+
+	stats := make([]WalletStats, 0, len(accounts))
+	for i, acc := range accounts {
+		s := walletStats(acc, byAddress[acc.Address], ethPrice)
+
+		nonce, err := client.Client.NonceAt(context.Background(), acc.Address, nil)
+		if err != nil {
+			logger.GlobalLogger.Warnf("Failed to get nonce for %s: %v", acc.Address.Hex(), err)
+		}
+		s.TxCount = nonce
+
+		s.BalanceETH = new(big.Float)
+		if balances[i].Balance != nil {
+			s.BalanceETH = weiToEth(balances[i].Balance)
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+func walletStats(acc *account.Account, records []ethClient.JournalRecord, ethPrice *big.Float) WalletStats {
+	s := WalletStats{
+		AccountID: acc.AccountID,
+		Address:   acc.Address,
+	}
+
+	contracts := make(map[string]struct{})
+	days := make(map[string]struct{})
+	weeks := make(map[string]struct{})
+	months := make(map[string]struct{})
+	gasWei := new(big.Int)
+
+	for _, r := range records {
+		s.JournalTxs++
+		if fee, ok := new(big.Int).SetString(r.Fee, 10); ok {
+			gasWei.Add(gasWei, fee)
+		}
+
+		if r.Status != ethClient.TxStatusSuccess {
+			s.FailedTxs++
+			continue
+		}
+
+		if r.To != "" && !strings.EqualFold(r.To, r.Address) {
+			contracts[strings.ToLower(r.To)] = struct{}{}
+		}
+
+		year, week := r.Time.ISOWeek()
+		days[r.Time.Format("2006-01-02")] = struct{}{}
+		weeks[fmt.Sprintf("%d-%02d", year, week)] = struct{}{}
+		months[r.Time.Format("2006-01")] = struct{}{}
+
+		s.VolumeUSD += r.VolumeUSD
+		if r.Time.After(s.LastActivity) {
+			s.LastActivity = r.Time
+		}
+	}
+
+	s.UniqueContracts = len(contracts)
+	s.ActiveDays = len(days)
+	s.ActiveWeeks = len(weeks)
+	s.ActiveMonths = len(months)
+	s.GasETH = weiToEth(gasWei)
+	s.GasUSD, _ = new(big.Float).Mul(s.GasETH, ethPrice).Float64()
+
+	return s
+}
+
+func weiToEth(wei *big.Int) *big.Float {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
+}
+
+var header = []string{"ID", "Address", "Txs", "Journal", "Failed", "Contracts", "Days", "Weeks", "Months", "Volume $", "Gas ETH", "Gas $", "Balance ETH", "Last activity"}
+
+func row(s WalletStats) []string {
+	last := "-"
+	if !s.LastActivity.IsZero() {
+		last = s.LastActivity.Local().Format("2006-01-02 15:04")
+	}
+
+	return []string{
+		strconv.Itoa(s.AccountID),
+		s.Address.Hex(),
+		strconv.FormatUint(s.TxCount, 10),
+		strconv.Itoa(s.JournalTxs),
+		strconv.Itoa(s.FailedTxs),
+		strconv.Itoa(s.UniqueContracts),
+		strconv.Itoa(s.ActiveDays),
+		strconv.Itoa(s.ActiveWeeks),
+		strconv.Itoa(s.ActiveMonths),
+		strconv.FormatFloat(s.VolumeUSD, 'f', 2, 64),
+		s.GasETH.Text('f', 6),
+		strconv.FormatFloat(s.GasUSD, 'f', 2, 64),
+		s.BalanceETH.Text('f', 6),
+		last,
+	}
+}
+
+func FormatTable(stats []WalletStats) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, s := range stats {
+		fmt.Fprintln(w, strings.Join(row(s), "\t"))
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+func ExportCSV(path string, stats []WalletStats) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		if err := w.Write(row(s)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
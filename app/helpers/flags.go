@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"base/config"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Flags struct {
+	// Command is the first argument after the flags: "" runs the accounts, "validate" only
+	// checks the config files, "keys" manages the keystore. Args are the arguments after it.
+	Command string
+	Args    []string
+
+	DryRun           bool
+	Resume           bool
+	Fresh            bool
+	ResumeFailedOnly bool
+	ResumeAccounts   string
+
+	Profile           string
+	AccountConfigPath string
+	ConfigPath        string
+	StatePath         string
+	JournalPath       string
+	AnalyticsPath     string
+	TokenCachePath    string
+	KeystorePath      string
+}
+
+func ParseFlags() *Flags {
+	flags := &Flags{}
+
+	flag.BoolVar(&flags.DryRun, "dry-run", false, "simulate every transaction with eth_call/eth_estimateGas instead of sending it")
+	flag.BoolVar(&flags.Resume, "resume", false, "continue from the saved state without asking")
+	flag.BoolVar(&flags.Fresh, "fresh", false, "clear the saved state and generate new actions for every account")
+	flag.BoolVar(&flags.ResumeFailedOnly, "resume-failed-only", false, "only re-run failed steps from the saved state, drop the rest")
+	flag.StringVar(&flags.ResumeAccounts, "resume-accounts", "", "resume only these account IDs, e.g. 1,3,5-7; other accounts start fresh")
+	flag.StringVar(&flags.Profile, "profile", "", "use the files in profiles/<name>/ (env BASE_PROFILE)")
+	flag.StringVar(&flags.AccountConfigPath, "account-config", "", "wallets and modules file (env BASE_ACCOUNT_CONFIG)")
+	flag.StringVar(&flags.ConfigPath, "config", "", "main config file (env BASE_CONFIG)")
+	flag.StringVar(&flags.StatePath, "state", "", "resume state file (env BASE_STATE)")
+	flag.StringVar(&flags.JournalPath, "journal", "", "transaction journal file (env BASE_JOURNAL)")
+	flag.StringVar(&flags.AnalyticsPath, "analytics", "", "wallet report CSV file (env BASE_ANALYTICS)")
+	flag.StringVar(&flags.TokenCachePath, "token-cache", "", "token metadata cache file (env BASE_TOKEN_CACHE)")
+	flag.StringVar(&flags.KeystorePath, "keystore", "", "encrypted keys: a vault .json file or a directory of keystore v3 files (env BASE_KEYSTORE)")
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flags.Command, flags.Args = flag.Arg(0), flag.Args()[1:]
+	}
+
+	return flags
+}
+
+// ResumePolicy returns the policy chosen on the command line, or "" when none was given.
+func (f *Flags) ResumePolicy() (string, error) {
+	policy := ""
+	for _, choice := range []struct {
+		set    bool
+		policy string
+	}{
+		{f.Resume, "resume"},
+		{f.Fresh, "fresh"},
+		{f.ResumeFailedOnly, "resume_failed_only"},
+	} {
+		if !choice.set {
+			continue
+		}
+		if policy != "" {
+			return "", errors.New("флаги --resume, --fresh и --resume-failed-only нельзя использовать вместе")
+		}
+		policy = choice.policy
+	}
+	return policy, nil
+}
+
+// ResumeInit picks the resume policy: command line flags first, then state.resume_policy from
+// the config, then "ask". --resume-accounts without a policy means resume.
+func ResumeInit(flags *Flags, cfg *config.Config) (string, map[int]bool, error) {
+	policy, err := flags.ResumePolicy()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ids, err := ParseAccountIDs(flags.ResumeAccounts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if policy == "" && len(ids) > 0 {
+		policy = "resume"
+	}
+	if policy == "" {
+		policy = cfg.StateConfig.ResumePolicy
+	}
+	if policy == "" {
+		policy = "ask"
+	}
+	if policy == "fresh" && len(ids) > 0 {
+		return "", nil, errors.New("--resume-accounts нельзя использовать вместе с --fresh")
+	}
+
+	return policy, ids, nil
+}
+
+// ParseAccountIDs parses a list like "1,3,5-7".
+func ParseAccountIDs(list string) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("неверный ID аккаунта %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || last < first {
+				return nil, fmt.Errorf("неверный диапазон аккаунтов %q", part)
+			}
+		}
+
+		for id := first; id <= last; id++ {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
@@ -2,11 +2,20 @@ package helpers
 
 import (
 	"base/account"
+	"base/actions"
 	"base/config"
 	"base/ethClient"
+	"base/httpClient"
 	"base/logger"
+	"base/modules"
+	"base/modules/dex"
 	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func PrintStartupMessages() {
@@ -14,22 +23,27 @@ func PrintStartupMessages() {
 	time.Sleep(5 * time.Second)
 }
 
-func AllPathInit() (string, string, string, error) {
-	accConfigPath := "account/account_config.json"
-	configPath := "config/config.json"
-	stateFilePath := "app/process/state.json"
-
-	return accConfigPath, configPath, stateFilePath, nil
-}
-
-func AccsInit(accConfigPath string) ([]*account.Account, *account.RandomConfig, error) {
+func AccsInit(accConfigPath, keystorePath string) ([]*account.Account, *account.RandomConfig, error) {
 	accConfig, err := account.LoadRandomConfig(accConfigPath)
 	if err != nil {
 		logger.GlobalLogger.Fatalf("не удалось загрузить конфигурацию для рандомизации: %v", err)
 	}
 	logger.GlobalLogger.Info("конфигурация рандомизации успешно загружена.")
 
-	accounts, err := account.CreateAccounts(accConfig.Wallets)
+	for _, key := range actions.UnknownModules(accConfig.Modules) {
+		logger.GlobalLogger.Warnf("неизвестный модуль в конфигурации: %s", key)
+	}
+
+	if accConfig.Wallets, err = account.ExpandWallets(accConfig.Wallets); err != nil {
+		return nil, nil, err
+	}
+
+	keys, err := walletKeys(accConfig.Wallets, keystorePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accounts, err := account.CreateAccounts(accConfig.Wallets, keys)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -37,14 +51,40 @@ func AccsInit(accConfigPath string) ([]*account.Account, *account.RandomConfig,
 	return accounts, accConfig, nil
 }
 
-func ClientsInit() (map[string]*ethClient.Client, error) {
+func ClientsInit(cfg *config.Config, paths *Paths, dryRun bool) (map[string]*ethClient.Client, error) {
+	tokens, err := ethClient.NewTokenRegistry(paths.TokenCache, baseTokens(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки реестра токенов: %v", err)
+	}
+
+	journal, err := ethClient.NewJournal(paths.Journal)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия журнала транзакций: %v", err)
+	}
+
 	var clients = make(map[string]*ethClient.Client)
-	for chain, rpc := range config.RPCs {
-		client, err := ethClient.NewClient(rpc, "account/account_stats.txt")
+	for _, chain := range cfg.Chains.Names() {
+		if len(cfg.RPCsFor(chain)) == 0 {
+			continue
+		}
+		client, err := ethClient.NewClient(chain, cfg.RPCsFor(chain))
 		if err != nil {
 			logger.GlobalLogger.Errorf("Ошибка создания eth client для сети %s: %v", chain, err)
 			continue
 		}
+
+		if gasCfg, ok := cfg.GasFor(chain); ok {
+			strategy, err := ethClient.NewGasStrategy(gasCfg)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка настройки газа для сети %s: %v", chain, err)
+			}
+			client.Gas = strategy
+		}
+		client.Tokens = tokens
+		client.Journal = journal
+		client.Replacement = ethClient.NewReplacementPolicy(cfg.TxConfig)
+		client.DryRun = dryRun
+
 		clients[chain] = client
 	}
 
@@ -54,3 +94,104 @@ func ClientsInit() (map[string]*ethClient.Client, error) {
 
 	return clients, nil
 }
+
+// ProxiesInit registers the global proxy and the wallets' own proxies and checks each of them
+// once. A proxy that is down is only skipped, the wallet moves on to its next one.
+func ProxiesInit(cfg *config.Config, accounts []*account.Account) (*httpClient.ProxyPool, error) {
+	proxyCfg := cfg.ProxyConfig
+	pool, err := httpClient.NewProxyPool(cfg.Chains.Proxy, proxyCfg.CheckURL, time.Duration(proxyCfg.CooldownSec)*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка в настройке proxy: %v", err)
+	}
+
+	var own int
+	for _, acc := range accounts {
+		if err := pool.Add(acc.Proxies...); err != nil {
+			return nil, fmt.Errorf("ошибка в прокси аккаунта %d: %v", acc.AccountID, err)
+		}
+		if len(acc.Proxies) > 0 {
+			own++
+		}
+	}
+
+	if own > 0 || cfg.Chains.Proxy != "" {
+		if failed := pool.Check(); failed > 0 {
+			logger.GlobalLogger.Warnf("Недоступных прокси: %d, запросы пойдут через запасные.", failed)
+		}
+		pool.StartHealthCheck(time.Duration(proxyCfg.CheckIntervalSec) * time.Second)
+		logger.GlobalLogger.Infof("Аккаунтов со своими прокси: %d из %d.", own, len(accounts))
+	}
+
+	return pool, nil
+}
+
+func baseTokens(cfg *config.Config) map[string]common.Address {
+	base, _ := cfg.Chains.Chain("base")
+	return base.Tokens
+}
+
+func SwapTokensInit(cfg *config.Config, clients map[string]*ethClient.Client) ([]common.Address, error) {
+	baseClient, ok := clients["base"]
+	if !ok {
+		return nil, errors.New("нет клиента для сети base")
+	}
+
+	tokens, err := baseClient.Tokens.Addresses(cfg.SwapTokenNames())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка в swap_tokens: %v", err)
+	}
+
+	return tokens, nil
+}
+
+func PricesInit(cfg *config.Config, clients map[string]*ethClient.Client, mods *modules.Modules) error {
+	baseClient, ok := clients["base"]
+	if !ok {
+		return errors.New("нет клиента для сети base")
+	}
+
+	static := make(ethClient.StaticPriceSource, len(cfg.Chains.FallbackPrices))
+	for token, price := range cfg.Chains.FallbackPrices {
+		static[token] = price
+	}
+	for key, price := range cfg.PriceConfig.StaticUSD {
+		token, ok := baseClient.Tokens.Resolve(key)
+		if !ok {
+			return fmt.Errorf("неизвестный токен в prices.static_usd: %s", key)
+		}
+		static[token] = big.NewFloat(price)
+	}
+
+	feeds := make(map[common.Address]common.Address, len(cfg.Chains.ChainlinkFeeds))
+	for token, feed := range cfg.Chains.ChainlinkFeeds {
+		feeds[token] = feed
+	}
+	for key, feed := range cfg.PriceConfig.Chainlink {
+		token, ok := baseClient.Tokens.Resolve(key)
+		if !ok || !common.IsHexAddress(feed) {
+			return fmt.Errorf("некорректный фид в prices.chainlink: %s -> %s", key, feed)
+		}
+		feeds[token] = common.HexToAddress(feed)
+	}
+
+	names := cfg.PriceConfig.Sources
+	if len(names) == 0 {
+		names = []string{"chainlink", "quoter"}
+	}
+
+	var sources []ethClient.PriceSource
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "chainlink":
+			sources = append(sources, ethClient.ChainlinkPriceSource{Feeds: feeds})
+		case "quoter":
+			sources = append(sources, dex.NewQuoterPriceSource(modules.Get[*dex.V3Router](*mods, dex.UniswapModule)))
+		default:
+			return fmt.Errorf("неизвестный источник цен: %s", name)
+		}
+	}
+
+	ttl := time.Duration(cfg.PriceConfig.CacheTTLSec) * time.Second
+	baseClient.Prices = ethClient.NewPriceOracle(ttl, static, sources...)
+	return nil
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"base/account"
+	"base/account/vault"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	PassphraseEnv    = "BASE_KEYSTORE_PASSWORD"
+	NewPassphraseEnv = "BASE_KEYSTORE_NEW_PASSWORD"
+)
+
+// ReadPassphrase takes the passphrase from the env variable, from the file named by the same
+// variable with a _FILE suffix (docker secrets), or asks for it when stdin is a terminal.
+func ReadPassphrase(env, prompt string) (string, error) {
+	if passphrase := os.Getenv(env); passphrase != "" {
+		return passphrase, nil
+	}
+	if file := os.Getenv(env + "_FILE"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("не удалось прочитать пароль из %s: %v", file, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "", fmt.Errorf("нет терминала для ввода пароля, задайте %s или %s_FILE", env, env)
+	}
+
+	fmt.Print(prompt)
+	// hide the input where stty exists, the passphrase is still read if it does not
+	if setEcho(false) == nil {
+		defer func() {
+			setEcho(true)
+			fmt.Println()
+		}()
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("не удалось прочитать пароль: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ReadNewPassphrase reads a passphrase that is about to encrypt keys. Typed in a terminal it is
+// asked twice, so a typo does not lock the keys away.
+func ReadNewPassphrase(env, prompt string) (string, error) {
+	passphrase, err := ReadPassphrase(env, prompt)
+	if err != nil || os.Getenv(env) != "" || os.Getenv(env+"_FILE") != "" {
+		return passphrase, err
+	}
+	if passphrase == "" {
+		return "", errors.New("пароль не может быть пустым")
+	}
+
+	confirm, err := ReadPassphrase(env, "Повторите пароль: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", errors.New("пароли не совпадают")
+	}
+	return passphrase, nil
+}
+
+func setEcho(on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// OpenKeystore asks for the passphrase and opens the store at path.
+func OpenKeystore(path string) (vault.Store, error) {
+	passphrase, err := ReadPassphrase(PassphraseEnv, fmt.Sprintf("Пароль хранилища ключей %s: ", path))
+	if err != nil {
+		return nil, err
+	}
+	store, err := vault.Open(path, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть хранилище ключей %s: %v", path, err)
+	}
+	return store, nil
+}
+
+// walletKeys opens the keystore only when some wallet has neither a private_key nor a mnemonic.
+func walletKeys(wallets []account.WalletConfig, keystorePath string) (account.KeySource, error) {
+	for _, wallet := range wallets {
+		if wallet.PrivateKey != "" || wallet.Mnemonic != "" {
+			continue
+		}
+		if _, err := os.Stat(keystorePath); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("кошельки указаны адресом или меткой, но хранилища ключей %s нет", keystorePath)
+		}
+		return OpenKeystore(keystorePath)
+	}
+	return nil, nil
+}
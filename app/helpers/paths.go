@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const profilesDir = "profiles"
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Paths lists every file the program reads or writes.
+type Paths struct {
+	AccountConfig string
+	Config        string
+	State         string
+	Journal       string
+	Analytics     string
+	TokenCache    string
+	Keystore      string
+}
+
+func defaultPaths() Paths {
+	return Paths{
+		AccountConfig: "account/account_config.json",
+		Config:        "config/config.json",
+		State:         "app/process/state.json",
+		Journal:       "account/journal.jsonl",
+		Analytics:     "account/analytics.csv",
+		TokenCache:    "config/token_cache.json",
+		Keystore:      "account/vault.json",
+	}
+}
+
+// profilePaths keeps everything of the profile in profiles/<name>/. A profile without its own
+// config.json uses the shared one.
+func profilePaths(name string) Paths {
+	dir := filepath.Join(profilesDir, name)
+	paths := Paths{
+		AccountConfig: filepath.Join(dir, "account_config.json"),
+		Config:        filepath.Join(dir, "config.json"),
+		State:         filepath.Join(dir, "state.json"),
+		Journal:       filepath.Join(dir, "journal.jsonl"),
+		Analytics:     filepath.Join(dir, "analytics.csv"),
+		TokenCache:    filepath.Join(dir, "token_cache.json"),
+		Keystore:      filepath.Join(dir, "vault.json"),
+	}
+	if _, err := os.Stat(paths.Config); errors.Is(err, os.ErrNotExist) {
+		paths.Config = defaultPaths().Config
+	}
+	return paths
+}
+
+// AllPathInit resolves every path in order of precedence: command line flag, BASE_* environment
+// variable, profile directory, built-in default. Directories for written files are created.
+func AllPathInit(flags *Flags) (*Paths, error) {
+	profile := firstNonEmpty(flags.Profile, os.Getenv("BASE_PROFILE"))
+
+	paths := defaultPaths()
+	if profile != "" {
+		if !profileNameRe.MatchString(profile) {
+			return nil, fmt.Errorf("недопустимое имя профиля %q", profile)
+		}
+		paths = profilePaths(profile)
+	}
+
+	paths.AccountConfig = firstNonEmpty(flags.AccountConfigPath, os.Getenv("BASE_ACCOUNT_CONFIG"), paths.AccountConfig)
+	paths.Config = firstNonEmpty(flags.ConfigPath, os.Getenv("BASE_CONFIG"), paths.Config)
+	paths.State = firstNonEmpty(flags.StatePath, os.Getenv("BASE_STATE"), paths.State)
+	paths.Journal = firstNonEmpty(flags.JournalPath, os.Getenv("BASE_JOURNAL"), paths.Journal)
+	paths.Analytics = firstNonEmpty(flags.AnalyticsPath, os.Getenv("BASE_ANALYTICS"), paths.Analytics)
+	paths.TokenCache = firstNonEmpty(flags.TokenCachePath, os.Getenv("BASE_TOKEN_CACHE"), paths.TokenCache)
+	paths.Keystore = firstNonEmpty(flags.KeystorePath, os.Getenv("BASE_KEYSTORE"), paths.Keystore)
+
+	// checked after the overrides, a profile may take its wallets from --account-config
+	if profile != "" {
+		if _, err := os.Stat(paths.AccountConfig); err != nil {
+			return nil, fmt.Errorf("профиль %s: нет файла кошельков %s", profile, paths.AccountConfig)
+		}
+	}
+
+	for _, path := range []string{paths.State, paths.Journal, paths.Analytics, paths.TokenCache} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("не удалось создать папку для %s: %v", path, err)
+		}
+	}
+
+	return &paths, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"base/config"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Scheduler plans an account's actions over days and weeks: a random number of active days per
+// week, a random number of actions per active day, and only within the configured local hours.
+type Scheduler struct {
+	cfg config.ScheduleConfig
+	loc *time.Location
+}
+
+func NewScheduler(cfg config.ScheduleConfig) (*Scheduler, error) {
+	switch {
+	case cfg.ActionsPerDayMin < 1 || cfg.ActionsPerDayMin > cfg.ActionsPerDayMax:
+		return nil, errors.New("actions_per_day_min должен быть не меньше 1 и не больше actions_per_day_max")
+	case cfg.ActiveDaysWeekMin < 1 || cfg.ActiveDaysWeekMin > cfg.ActiveDaysWeekMax || cfg.ActiveDaysWeekMax > 7:
+		return nil, errors.New("active_days_per_week_min должен быть не меньше 1, не больше active_days_per_week_max, а тот не больше 7")
+	case cfg.HourFrom < 0 || cfg.HourFrom >= cfg.HourTo || cfg.HourTo > 24:
+		return nil, errors.New("часы активности должны удовлетворять 0 <= hour_from < hour_to <= 24")
+	}
+
+	loc := time.Local
+	if cfg.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("неизвестная временная зона %s: %v", cfg.Timezone, err)
+		}
+	}
+
+	return &Scheduler{cfg: cfg, loc: loc}, nil
+}
+
+// Plan returns a start time for each of n actions, in order, none of them before from.
+func (s *Scheduler) Plan(n int, from time.Time) []time.Time {
+	from = from.In(s.loc)
+	times := make([]time.Time, 0, n)
+
+	for week := dayStart(from); len(times) < n; week = week.AddDate(0, 0, 7) {
+		var days []time.Time
+		for i := 0; i < 7; i++ {
+			day := week.AddDate(0, 0, i)
+			// a day whose window is almost over would cram its actions together
+			if s.windowEnd(day).After(from.Add(time.Hour)) {
+				days = append(days, day)
+			}
+		}
+
+		activeDays := randomBetween(s.cfg.ActiveDaysWeekMin, s.cfg.ActiveDaysWeekMax)
+		rand.Shuffle(len(days), func(i, j int) { days[i], days[j] = days[j], days[i] })
+		days = days[:min(activeDays, len(days))]
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+		for _, day := range days {
+			perDay := min(randomBetween(s.cfg.ActionsPerDayMin, s.cfg.ActionsPerDayMax), n-len(times))
+			if perDay <= 0 {
+				break
+			}
+
+			start, end := s.windowStart(day), s.windowEnd(day)
+			if start.Before(from) {
+				start = from
+			}
+
+			// one action in each equal slot of the window keeps them apart
+			slot := end.Sub(start) / time.Duration(perDay)
+			for i := 0; i < perDay; i++ {
+				offset := time.Duration(i) * slot
+				if slot > 0 {
+					offset += time.Duration(rand.Int63n(int64(slot)))
+				}
+				times = append(times, start.Add(offset))
+			}
+		}
+	}
+
+	return times
+}
+
+// Intervals converts planned times into waits between consecutive actions, starting from from.
+func Intervals(from time.Time, times []time.Time) []time.Duration {
+	intervals := make([]time.Duration, len(times))
+	prev := from
+	for i, t := range times {
+		if t.After(prev) {
+			intervals[i] = t.Sub(prev)
+		}
+		prev = t
+	}
+	return intervals
+}
+
+func (s *Scheduler) windowStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), s.cfg.HourFrom, 0, 0, 0, s.loc)
+}
+
+func (s *Scheduler) windowEnd(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), s.cfg.HourTo, 0, 0, 0, s.loc)
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func randomBetween(min, max int) int {
+	return min + rand.Intn(max-min+1)
+}
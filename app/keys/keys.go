@@ -0,0 +1,195 @@
+package keys
+
+import (
+	"base/account"
+	"base/account/vault"
+	"base/app/helpers"
+	"base/utils"
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `использование:
+  keys import [file]  добавить ключи: из файла (строки "<ключ>" или "<метка> <ключ>", "-" - stdin)
+                      или, без файла, все private_key из конфига кошельков
+  keys list           показать адреса и метки
+  keys reencrypt      перешифровать ключи новым паролем`
+
+// Run executes a "keys" subcommand against the keystore at keystorePath.
+func Run(args []string, keystorePath, accConfigPath string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "import":
+		if len(args) > 2 {
+			return errors.New(usage)
+		}
+		var file string
+		if len(args) == 2 {
+			file = args[1]
+		}
+		return importKeys(keystorePath, accConfigPath, file)
+	case "list":
+		return listKeys(keystorePath)
+	case "reencrypt":
+		return reencrypt(keystorePath)
+	default:
+		return fmt.Errorf("неизвестная команда keys %s\n%s", args[0], usage)
+	}
+}
+
+type importedKey struct {
+	label string
+	key   *ecdsa.PrivateKey
+}
+
+func importKeys(keystorePath, accConfigPath, file string) error {
+	var (
+		keys []importedKey
+		err  error
+	)
+	if file == "" {
+		keys, err = configKeys(accConfigPath)
+	} else {
+		keys, err = fileKeys(file)
+	}
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.New("нет ключей для импорта")
+	}
+
+	store, err := openForImport(keystorePath)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		address, err := store.Import(k.key, k.label)
+		if err != nil {
+			return fmt.Errorf("не удалось импортировать ключ %s: %v", utils.DeriveAddress(k.key).Hex(), err)
+		}
+		fmt.Printf("%s\t%s\n", address.Hex(), k.label)
+	}
+	fmt.Printf("Импортировано ключей: %d в %s. Замените private_key в конфиге кошельков на \"address\" (или \"label\") и удалите ключи из открытых файлов.\n", len(keys), keystorePath)
+	return nil
+}
+
+// openForImport asks for the passphrase twice when the keystore is about to be created.
+func openForImport(keystorePath string) (vault.Store, error) {
+	if _, err := os.Stat(keystorePath); err == nil {
+		return helpers.OpenKeystore(keystorePath)
+	}
+
+	passphrase, err := helpers.ReadNewPassphrase(helpers.PassphraseEnv, fmt.Sprintf("Пароль для нового хранилища ключей %s: ", keystorePath))
+	if err != nil {
+		return nil, err
+	}
+	return vault.Open(keystorePath, passphrase)
+}
+
+func configKeys(accConfigPath string) ([]importedKey, error) {
+	accConfig, err := account.LoadRandomConfig(accConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить конфиг кошельков %s: %v", accConfigPath, err)
+	}
+
+	var keys []importedKey
+	for i, wallet := range accConfig.Wallets {
+		if wallet.PrivateKey == "" {
+			continue
+		}
+		key, err := utils.ParsePrivateKey(wallet.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный приватный ключ кошелька %d: %v", i+1, err)
+		}
+		keys = append(keys, importedKey{label: wallet.Label, key: key})
+	}
+	return keys, nil
+}
+
+func fileKeys(file string) ([]importedKey, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var keys []importedKey
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var label, hexKey string
+		switch len(fields) {
+		case 1:
+			hexKey = fields[0]
+		case 2:
+			label, hexKey = fields[0], fields[1]
+		default:
+			return nil, fmt.Errorf("строка %d: ожидается \"<ключ>\" или \"<метка> <ключ>\"", line)
+		}
+
+		key, err := utils.ParsePrivateKey(strings.TrimPrefix(hexKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: некорректный приватный ключ: %v", line, err)
+		}
+		keys = append(keys, importedKey{label: label, key: key})
+	}
+	return keys, scanner.Err()
+}
+
+func listKeys(keystorePath string) error {
+	if _, err := os.Stat(keystorePath); err != nil {
+		return fmt.Errorf("хранилища ключей %s нет", keystorePath)
+	}
+	store, err := helpers.OpenKeystore(keystorePath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fmt.Printf("%s\t%s\n", entry.Address.Hex(), entry.Label)
+	}
+	fmt.Printf("Кошельков в хранилище: %d.\n", len(entries))
+	return nil
+}
+
+func reencrypt(keystorePath string) error {
+	if _, err := os.Stat(keystorePath); err != nil {
+		return fmt.Errorf("хранилища ключей %s нет", keystorePath)
+	}
+	store, err := helpers.OpenKeystore(keystorePath)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := helpers.ReadNewPassphrase(helpers.NewPassphraseEnv, "Новый пароль хранилища ключей: ")
+	if err != nil {
+		return err
+	}
+	if err := store.Reencrypt(passphrase); err != nil {
+		return err
+	}
+	fmt.Printf("Ключи в %s перешифрованы новым паролем.\n", keystorePath)
+	return nil
+}
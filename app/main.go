@@ -2,17 +2,27 @@ package main
 
 import (
 	"base/account"
+	"base/actions"
+	// the handlers register the action modules and, through their imports, the protocol modules
+	_ "base/actions/handlers"
 	"base/actions/randomization"
 	cfg "base/config"
 	"base/ethClient"
+	"base/httpClient"
 	"base/logger"
 	"base/modules"
+	"context"
 	"math/rand"
-	"sync"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"base/app/analyzer"
 	"base/app/helpers"
+	"base/app/keys"
 	"base/app/process"
+	"base/app/validate"
 )
 
 func init() {
@@ -20,54 +30,161 @@ func init() {
 }
 
 func main() {
+	flags := helpers.ParseFlags()
 	helpers.PrintStartupMessages()
 
-	accConfigPath, configPath, statePath, err := helpers.AllPathInit()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// restore the default handler, so a second signal kills the process right away
+		stop()
+		logger.GlobalLogger.Warn("Получен сигнал остановки: новые действия не запускаются, ждем подтверждения отправленных транзакций. Повторный сигнал завершит программу немедленно.")
+	}()
+
+	paths, err := helpers.AllPathInit(flags)
 	if err != nil {
 		logger.GlobalLogger.Fatalf("Ошибка инициализации путей конфигурационных файлов: %v", err)
 	}
+	logger.GlobalLogger.Infof("Файлы: кошельки %s, конфиг %s, состояние %s, журнал %s.", paths.AccountConfig, paths.Config, paths.State, paths.Journal)
+
+	switch flags.Command {
+	case "":
+	case "validate":
+		os.Exit(runValidate(ctx, paths))
+	case "keys":
+		if err := keys.Run(flags.Args, paths.Keystore, paths.AccountConfig); err != nil {
+			logger.GlobalLogger.Fatal(err)
+		}
+		return
+	default:
+		logger.GlobalLogger.Fatalf("неизвестная команда %q, доступны validate и keys", flags.Command)
+	}
 
-	accounts, accConfig, err := helpers.AccsInit(accConfigPath)
+	accounts, accConfig, err := helpers.AccsInit(paths.AccountConfig, paths.Keystore)
 	if err != nil {
 		logger.GlobalLogger.Fatalf("ошибка создания аккаунтов: %v", err)
 	}
 
-	config, err := cfg.LoadConfig(configPath)
+	config, err := cfg.LoadConfig(paths.Config)
 	if err != nil {
 		logger.GlobalLogger.Fatalf("ошибка загрузки основного конфига, проверьте его целостность: %v", err)
 	}
+	cfg.Chains = config.Chains
 	logger.GlobalLogger.Info("Основная конфигурация успешно загружена.")
 
-	clients, err := helpers.ClientsInit()
+	clients, err := helpers.ClientsInit(config, paths, flags.DryRun)
 	if err != nil {
 		logger.GlobalLogger.Fatal(err)
 	}
 	defer ethClient.CloseAllClients(clients)
 
-	mods, err := modules.InitializeModules(*config, clients)
+	proxies, err := helpers.ProxiesInit(config, accounts)
+	if err != nil {
+		logger.GlobalLogger.Fatal(err)
+	}
+	defer proxies.Close()
+
+	mods, err := modules.InitializeModules(*config, clients, proxies)
 	if err != nil {
 		logger.GlobalLogger.Fatalf("ошибка инициализации модулей: %v", err)
 	}
+	if err := helpers.PricesInit(config, clients, mods); err != nil {
+		logger.GlobalLogger.Fatalf("ошибка настройки источников цен: %v", err)
+	}
+
 	logger.GlobalLogger.Info("Все модули успешно инициализированы. Спим 2 секунды.")
 	time.Sleep(time.Second * 2)
 
-	memoryHandler := process.NewMemory(statePath)
+	retryPolicies, err := actions.NewRetryPolicies(config.RetryConfig)
+	if err != nil {
+		logger.GlobalLogger.Fatalf("ошибка в настройках retry: %v", err)
+	}
+
+	var scheduler *helpers.Scheduler
+	if config.ScheduleConfig.Enabled {
+		if scheduler, err = helpers.NewScheduler(config.ScheduleConfig); err != nil {
+			logger.GlobalLogger.Fatalf("ошибка в настройках schedule: %v", err)
+		}
+	}
+
+	memoryHandler := process.NewMemory(process.NewFileStateStore(paths.State))
+
+	resumePolicy, resumeAccounts, err := helpers.ResumeInit(flags, config)
+	if err != nil {
+		logger.GlobalLogger.Fatal(err)
+	}
+	if resumePolicy == process.ResumeFailedOnly {
+		config.StateConfig.RetryFailed = true
+	}
+
+	if flags.DryRun {
+		logger.GlobalLogger.Warn("Режим dry-run: транзакции только симулируются, состояние не сохраняется.")
+	} else if err := process.ApplyResumePolicy(memoryHandler, process.ResumeOptions{Policy: resumePolicy, AccountIDs: resumeAccounts}); err != nil {
+		logger.GlobalLogger.Fatalf("ошибка подготовки состояния: %v", err)
+	}
 
-	process.UploadOldAction(memoryHandler)
+	if resumePolicy == process.ResumeFailedOnly {
+		if accounts, err = process.WithState(memoryHandler, accounts); err != nil {
+			logger.GlobalLogger.Fatalf("ошибка чтения состояния: %v", err)
+		}
+		logger.GlobalLogger.Infof("Аккаунтов с неудачными действиями: %d.", len(accounts))
+	}
+
+	swapTokens, err := helpers.SwapTokensInit(config, clients)
+	if err != nil {
+		logger.GlobalLogger.Fatal(err)
+	}
 
 	availableNFTs := account.InitializeAvailableNFTs(accConfig)
-	randomizer := randomization.NewRandomizer(cfg.AviableTokens, availableNFTs, clients)
+	randomizer := randomization.NewRandomizer(swapTokens, availableNFTs, clients)
 
-	var wg sync.WaitGroup
-	for _, acc := range accounts {
-		wg.Add(1)
-		go func(acc *account.Account) {
-			defer wg.Done()
-			process.ProcessAccount(acc, accConfig, config, clients, randomizer, mods, memoryHandler)
-		}(acc)
+	if flags.DryRun {
+		// waits are skipped in dry-run, the start window included
+		config.ConcurrencyConfig.StartDelayMinSec, config.ConcurrencyConfig.StartDelayMaxSec = 0, 0
+	}
+	err = process.RunAccounts(ctx, accounts, config.ConcurrencyConfig, func(ctx context.Context, acc *account.Account, slot *process.Slot) {
+		if config.ProxyConfig.RPC {
+			ctx = httpClient.WithTransport(ctx, proxies.Transport(acc.Proxies))
+		}
+		process.ProcessAccount(ctx, acc, accConfig, config, clients, randomizer, mods, memoryHandler, retryPolicies, scheduler, slot, flags.DryRun)
+	})
+	if err != nil {
+		logger.GlobalLogger.Fatalf("ошибка в настройках concurrency: %v", err)
+	}
+
+	if ctx.Err() != nil {
+		logger.GlobalLogger.Info("Все аккаунты остановлены, состояние сохранено. Программа завершает работу.")
+		return
+	}
+
+	logger.GlobalLogger.Infof("Анализ аккаунтов...")
+	stats, err := analyzer.Analyze(accounts, clients["base"], paths.Journal)
+	if err != nil {
+		logger.GlobalLogger.Errorf("Ошибка анализа аккаунтов: %v", err)
+	} else {
+		logger.GlobalLogger.Infof("Статистика кошельков в Base:\n%s", analyzer.FormatTable(stats))
+		if err := analyzer.ExportCSV(paths.Analytics, stats); err != nil {
+			logger.GlobalLogger.Errorf("Ошибка экспорта статистики в CSV: %v", err)
+		} else {
+			logger.GlobalLogger.Infof("Статистика сохранена в %s", paths.Analytics)
+		}
 	}
 
-	wg.Wait()
 	logger.GlobalLogger.Infof("Все действия выполнены. Программа завершает работу.")
 	logger.GlobalLogger.Info(cfg.Subscribe)
 }
+
+func runValidate(ctx context.Context, paths *helpers.Paths) int {
+	problems := validate.Run(ctx, paths.AccountConfig, paths.Config, paths.Keystore)
+	if len(problems) == 0 {
+		logger.GlobalLogger.Info("Проблем в конфигурации не найдено.")
+		return 0
+	}
+
+	for _, problem := range problems {
+		logger.GlobalLogger.Error(problem.String())
+	}
+	logger.GlobalLogger.Errorf("Найдено проблем в конфигурации: %d.", len(problems))
+	return 1
+}
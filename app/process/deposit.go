@@ -2,13 +2,16 @@ package process
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/handlers"
 	"base/actions/types"
 	"base/config"
 	"base/ethClient"
 	"base/logger"
 	"base/modules"
+	"base/modules/refuel"
 	"base/utils"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -17,60 +20,62 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-func bridgeToBase(acc *account.Account, mainConfig *config.Config, clients map[string]*ethClient.Client, mods *modules.Modules) error {
-	if err := ensureRefuelIfNeeded(acc, clients, mods); err != nil {
+func bridgeToBase(ctx context.Context, acc *account.Account, mainConfig *config.Config, clients map[string]*ethClient.Client, mods *modules.Modules, dryRun bool) error {
+	// the config may use an alias such as "eth", clients are keyed by the registry name
+	chain, ok := config.Chains.Chain(acc.Bridge)
+	if !ok {
+		return fmt.Errorf("неизвестная сеть для бриджа: %s", acc.Bridge)
+	}
+	client, ok := clients[chain.Name]
+	if !ok {
+		return fmt.Errorf("нет клиента для сети %s", chain.Name)
+	}
+
+	if err := checkRefuel(ctx, acc, clients, mods); err != nil {
 		return err
 	}
 
-	tokenAddress, err := getBridgeTokenAddress(acc)
+	tokenAddress, err := getBridgeTokenAddress(acc, chain)
 	if err != nil {
 		return fmt.Errorf("ошибка получения токена для бриджа %v", err)
 	}
 
-	amountToBridge, err := calculateBridgeAmount(acc, clients, tokenAddress)
+	amountToBridge, err := calculateBridgeAmount(ctx, acc, client, tokenAddress)
 	if err != nil {
 		return fmt.Errorf("ошибка расчета бриджа %v", err)
 	}
 
-	if err = approveIfNeeded(acc, clients, tokenAddress, amountToBridge); err != nil {
+	acc.SetCurrentAction("bridge_approve", actions.ModuleName(handlers.BridgeAction))
+	if err = approveIfNeeded(ctx, acc, chain, client, tokenAddress, amountToBridge); err != nil {
 		logger.GlobalLogger.Errorf("ошибка approve: %v", err)
 	}
 
-	time.Sleep(time.Second * 5)
+	if err := utils.Sleep(ctx, time.Second*5); err != nil {
+		return err
+	}
 
-	if err := executeBridge(acc, mainConfig, clients, mods, amountToBridge); err != nil {
+	acc.SetCurrentAction("bridge_to_base", actions.ModuleName(handlers.BridgeAction))
+	if err := executeBridge(ctx, acc, mainConfig, chain.Name, client, mods, amountToBridge); err != nil {
 		logger.GlobalLogger.Warnf("bridge error: %v", err)
 	}
 
-	waitAfterBridge(acc)
-
-	return nil
-}
-
-func checkRefuel(acc *account.Account, clients map[string]*ethClient.Client, mods *modules.Modules) error {
-	needsRefuel, maxChain, err := depositNativeIfNeeded(acc, clients)
-	if err != nil {
-		logger.GlobalLogger.Errorf("Ошибка проверки необходимости рефьюела: %v", err)
-		return err
+	if !dryRun {
+		return waitAfterBridge(ctx, chain.Name)
 	}
 
-	if needsRefuel {
-		if err := mods.Refuel.Refuel(maxChain, "base", acc); err != nil {
-			logger.GlobalLogger.Warnf("Ошибка депозита нативки в base: %v", err)
-			return err
-		}
-	}
 	return nil
 }
 
-func ensureRefuelIfNeeded(acc *account.Account, clients map[string]*ethClient.Client, mods *modules.Modules) error {
-	needsRefuel, maxChain, err := depositNativeIfNeeded(acc, clients)
+func checkRefuel(ctx context.Context, acc *account.Account, clients map[string]*ethClient.Client, mods *modules.Modules) error {
+	needsRefuel, maxChain, err := depositNativeIfNeeded(ctx, acc, clients)
 	if err != nil {
 		logger.GlobalLogger.Errorf("Ошибка проверки необходимости рефьюела: %v", err)
 		return err
 	}
+
 	if needsRefuel {
-		if err := mods.Refuel.Refuel(maxChain, "base", acc); err != nil {
+		acc.SetCurrentAction(string(handlers.RefuelAction), actions.ModuleName(handlers.RefuelAction))
+		if err := modules.Get[*refuel.Refuel](*mods, refuel.Module).Refuel(ctx, maxChain, "base", acc); err != nil {
 			logger.GlobalLogger.Warnf("Ошибка депозита нативки в base: %v", err)
 			return err
 		}
@@ -78,8 +83,8 @@ func ensureRefuelIfNeeded(acc *account.Account, clients map[string]*ethClient.Cl
 	return nil
 }
 
-func calculateBridgeAmount(acc *account.Account, clients map[string]*ethClient.Client, tokenAddress common.Address) (*big.Int, error) {
-	amount, err := clients[acc.Bridge].BalanceCheck(acc.Address, tokenAddress)
+func calculateBridgeAmount(ctx context.Context, acc *account.Account, client *ethClient.Client, tokenAddress common.Address) (*big.Int, error) {
+	amount, err := client.BalanceCheck(ctx, acc.Address, tokenAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed bridge: %v", err)
 	}
@@ -88,36 +93,39 @@ func calculateBridgeAmount(acc *account.Account, clients map[string]*ethClient.C
 	return amount, nil
 }
 
-func approveIfNeeded(acc *account.Account, clients map[string]*ethClient.Client, tokenAddress common.Address, amount *big.Int) error {
+func approveIfNeeded(ctx context.Context, acc *account.Account, chain *config.Chain, client *ethClient.Client, tokenAddress common.Address, amount *big.Int) error {
 	if utils.IsNativeTokenBySymbol(acc.TokenBridge) {
 		return nil
 	}
-	_, err := clients[acc.Bridge].ApproveTx(tokenAddress, config.LZ_Main_CA[acc.Bridge]["swap_ca"], acc, amount, false)
+	if chain.StargateRouter == (common.Address{}) {
+		return fmt.Errorf("stargate не поддерживается в сети %s", chain.Name)
+	}
+	_, err := client.ApproveTx(ctx, tokenAddress, chain.StargateRouter, acc, amount, false)
 	return err
 }
 
-func executeBridge(acc *account.Account, mainConfig *config.Config, clients map[string]*ethClient.Client, mods *modules.Modules, amountToBridge *big.Int) error {
+func executeBridge(ctx context.Context, acc *account.Account, mainConfig *config.Config, fromChain string, client *ethClient.Client, mods *modules.Modules, amountToBridge *big.Int) error {
 	return handlers.BridgeHandler.Execute(handlers.BridgeHandler{
 		BridgeParams: types.BridgeParams{
-			FromChain:      acc.Bridge,
+			FromChain:      fromChain,
 			DstChain:       "base",
+			Token:          acc.TokenBridge,
 			AmountToBridge: amountToBridge,
 		},
-	}, acc, *mods, clients[acc.Bridge], mainConfig)
+	}, ctx, acc, *mods, client, mainConfig)
 }
 
-func waitAfterBridge(acc *account.Account) {
-	if acc.Bridge == "polygon" {
+func waitAfterBridge(ctx context.Context, fromChain string) error {
+	if fromChain == "polygon" {
 		logger.GlobalLogger.Info("бридж окончен, спим 25 минут...")
-		time.Sleep(time.Minute * 25)
-	} else {
-		logger.GlobalLogger.Info("бридж окончен, спим 3 минуты...")
-		time.Sleep(time.Minute * 3)
+		return utils.Sleep(ctx, time.Minute*25)
 	}
+	logger.GlobalLogger.Info("бридж окончен, спим 3 минуты...")
+	return utils.Sleep(ctx, time.Minute*3)
 }
 
-func depositNativeIfNeeded(acc *account.Account, clients map[string]*ethClient.Client) (bool, string, error) {
-	balanceInBase, err := clients["base"].BalanceCheck(acc.Address, config.WETH)
+func depositNativeIfNeeded(ctx context.Context, acc *account.Account, clients map[string]*ethClient.Client) (bool, string, error) {
+	balanceInBase, err := clients["base"].BalanceCheck(ctx, acc.Address, config.Chains.Base.WETH)
 	if err != nil {
 		return false, "", err
 	}
@@ -127,7 +135,7 @@ func depositNativeIfNeeded(acc *account.Account, clients map[string]*ethClient.C
 		return false, "", nil
 	}
 
-	maxChain, maxBal, err := getMaxNativeBalanceChain(acc, clients)
+	maxChain, maxBal, err := getMaxNativeBalanceChain(ctx, acc, clients)
 	if err != nil {
 		return false, "", err
 	}
@@ -139,7 +147,7 @@ func depositNativeIfNeeded(acc *account.Account, clients map[string]*ethClient.C
 	return true, maxChain, nil
 }
 
-func getMaxNativeBalanceChain(acc *account.Account, clients map[string]*ethClient.Client) (string, *big.Int, error) {
+func getMaxNativeBalanceChain(ctx context.Context, acc *account.Account, clients map[string]*ethClient.Client) (string, *big.Int, error) {
 	maxChain := ""
 	maxBalance := big.NewInt(0)
 	for chain, client := range clients {
@@ -147,7 +155,7 @@ func getMaxNativeBalanceChain(acc *account.Account, clients map[string]*ethClien
 			continue
 		}
 
-		bal, err := client.BalanceCheck(acc.Address, config.WETH)
+		bal, err := client.BalanceCheck(ctx, acc.Address, config.Chains.Base.WETH)
 		if err != nil {
 			logger.GlobalLogger.Warnf("Ошибка получения баланса для сети %s: %v", chain, err)
 			continue
@@ -159,12 +167,14 @@ func getMaxNativeBalanceChain(acc *account.Account, clients map[string]*ethClien
 		}
 	}
 
-	time.Sleep(time.Second * 5)
+	if err := utils.Sleep(ctx, time.Second*5); err != nil {
+		return "", nil, err
+	}
 	return maxChain, maxBalance, nil
 }
 
-func getBridgeTokenAddress(acc *account.Account) (common.Address, error) {
-	tokenAddress, exists := config.OtherTokens[fmt.Sprintf("%s_%s", acc.Bridge, acc.TokenBridge)]
+func getBridgeTokenAddress(acc *account.Account, chain *config.Chain) (common.Address, error) {
+	tokenAddress, exists := chain.Token(acc.TokenBridge)
 	if !exists {
 		return common.Address{}, fmt.Errorf("токен '%s' не найден в конфигурации для аккаунта %d", acc.TokenBridge, acc.AccountID)
 	}
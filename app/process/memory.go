@@ -2,126 +2,87 @@ package process
 
 import (
 	"base/actions"
-	"encoding/json"
-	"fmt"
-	"io"
-	"os"
 	"sync"
 	"time"
 )
 
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+	OutcomeSkipped = "skipped"
+	// entries migrated from files that did not record outcomes
+	OutcomeUnknown = "unknown"
+)
+
+// ActionRecord is the result of one step of GeneratedActions, stored at the same index.
+type ActionRecord struct {
+	Action     actions.Action `json:"action"`
+	Outcome    string         `json:"outcome"`
+	Error      string         `json:"error,omitempty"`
+	TxHashes   []string       `json:"tx_hashes,omitempty"`
+	Attempts   int            `json:"attempts"`
+	FinishedAt time.Time      `json:"finished_at"`
+}
+
 type AccountState struct {
-	AccountID         int              `json:"account_id"`
-	CompletedActions  []actions.Action `json:"completed_actions"`
-	LastProcessedTime time.Time        `json:"last_processed_time"`
-	LastActionTime    time.Time        `json:"last_action_time"`
-	TotalElapsedTime  time.Duration    `json:"total_elapsed_time"`
-	ActionIntervals   []time.Duration  `json:"action_intervals"`
+	AccountID int `json:"account_id"`
+	// Address is the wallet the plan was made for. Account IDs are positions in the wallet list,
+	// so after the list changes the same ID may point at another wallet.
+	Address string `json:"address,omitempty"`
+
+	CompletedActions  []ActionRecord  `json:"completed_actions"`
+	LastProcessedTime time.Time       `json:"last_processed_time"`
+	LastActionTime    time.Time       `json:"last_action_time"`
+	TotalElapsedTime  time.Duration   `json:"total_elapsed_time"`
+	ActionIntervals   []time.Duration `json:"action_intervals"`
 
 	GeneratedActions   []actions.Action `json:"generated_actions"`
 	GeneratedDuration  time.Duration    `json:"generated_duration"`
 	GeneratedIntervals []time.Duration  `json:"generated_intervals"`
+	// ScheduledTimes holds the planned start of every generated action when the calendar
+	// scheduler is enabled, the intervals are then only informative.
+	ScheduledTimes []time.Time `json:"scheduled_times,omitempty"`
 
 	LastActionIndex int `json:"last_action_index"`
 }
 
 type Memory struct {
-	StateFilePath string
-	mu            sync.Mutex
+	store StateStore
+	mu    sync.Mutex
 }
 
-func NewMemory(stateFilePath string) *Memory {
-	return &Memory{StateFilePath: stateFilePath}
+func NewMemory(store StateStore) *Memory {
+	return &Memory{store: store}
 }
 
 func (m *Memory) SaveState(state *AccountState) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	file, err := os.OpenFile(m.StateFilePath, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	var states []AccountState
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&states); err != nil && err.Error() != "EOF" {
-		return err
-	}
-
-	var found bool
-	for i, existingState := range states {
-		if existingState.AccountID == state.AccountID {
-			states[i] = *state
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		states = append(states, *state)
-	}
-
-	file.Seek(0, 0)
-	file.Truncate(0)
-	encoder := json.NewEncoder(file)
-	return encoder.Encode(states)
+	return m.saveStateWithoutLock(state)
 }
 
 func (m *Memory) LoadState(accountID int) (*AccountState, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	file, err := os.Open(m.StateFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка открытия файла состояния: %v", err)
-	}
-	defer file.Close()
-
-	var states []AccountState
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&states)
-	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("ошибка декодирования файла состояния: %v", err)
-	}
-
-	for _, state := range states {
-		if state.AccountID == accountID {
-			return &state, nil
-		}
-	}
-	return nil, nil
+	return m.loadStateWithoutLock(accountID)
 }
 
 func (m *Memory) IsStateFileNotEmpty() (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	_, err := os.Stat(m.StateFilePath)
-	if os.IsNotExist(err) {
-		return false, nil
-	} else if err != nil {
-		return false, fmt.Errorf("ошибка доступа к файлу состояния: %v", err)
-	}
-
-	file, err := os.Open(m.StateFilePath)
+	states, err := m.store.Load()
 	if err != nil {
-		return false, fmt.Errorf("ошибка открытия файла состояния: %v", err)
-	}
-	defer file.Close()
-
-	var states []AccountState
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&states)
-	if err != nil && err != io.EOF {
-		return false, fmt.Errorf("ошибка декодирования файла состояния: %v", err)
+		return false, err
 	}
 
 	return len(states) > 0, nil
 }
 
-func (m *Memory) UpdateState(accountID int, completedAction actions.Action, interval time.Duration) error {
+// UpdateState stores the record of step index. A retried step overwrites its previous record.
+func (m *Memory) UpdateState(accountID, index int, record ActionRecord, interval time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -134,30 +95,44 @@ func (m *Memory) UpdateState(accountID int, completedAction actions.Action, inte
 		state = &AccountState{AccountID: accountID}
 	}
 
-	state.CompletedActions = append(state.CompletedActions, completedAction)
-	state.TotalElapsedTime += interval
+	if index < len(state.CompletedActions) {
+		state.CompletedActions[index] = record
+	} else {
+		state.CompletedActions = append(state.CompletedActions, record)
+		state.ActionIntervals = append(state.ActionIntervals, interval)
+		state.TotalElapsedTime += interval
+	}
 	state.LastProcessedTime = time.Now()
-	state.ActionIntervals = append(state.ActionIntervals, interval)
 	state.LastActionTime = time.Now()
-	state.LastActionIndex = len(state.CompletedActions)
+	state.LastActionIndex = index + 1
 
 	return m.saveStateWithoutLock(state)
 }
 
-func (m *Memory) loadStateWithoutLock(accountID int) (*AccountState, error) {
-	file, err := os.Open(m.StateFilePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
+// HasFailed reports whether any recorded step failed.
+func (s *AccountState) HasFailed() bool {
+	for _, record := range s.CompletedActions {
+		if record.Outcome == OutcomeFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the indices of the steps the next run will execute.
+func (s *AccountState) Pending(retryFailed bool) []int {
+	var pending []int
+	for i := range s.GeneratedActions {
+		if i >= len(s.CompletedActions) || (retryFailed && s.CompletedActions[i].Outcome == OutcomeFailed) {
+			pending = append(pending, i)
 		}
-		return nil, err
 	}
-	defer file.Close()
+	return pending
+}
 
-	var states []AccountState
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&states)
-	if err != nil && err.Error() != "EOF" {
+func (m *Memory) loadStateWithoutLock(accountID int) (*AccountState, error) {
+	states, err := m.store.Load()
+	if err != nil {
 		return nil, err
 	}
 
@@ -170,17 +145,10 @@ func (m *Memory) loadStateWithoutLock(accountID int) (*AccountState, error) {
 }
 
 func (m *Memory) saveStateWithoutLock(state *AccountState) error {
-	file, err := os.OpenFile(m.StateFilePath, os.O_RDWR|os.O_CREATE, 0644)
+	states, err := m.store.Load()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	var states []AccountState
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&states); err != nil && err.Error() != "EOF" {
-		return err
-	}
 
 	var found bool
 	for i, existingState := range states {
@@ -195,25 +163,15 @@ func (m *Memory) saveStateWithoutLock(state *AccountState) error {
 		states = append(states, *state)
 	}
 
-	file.Seek(0, 0)
-	file.Truncate(0)
-	encoder := json.NewEncoder(file)
-
-	return encoder.Encode(states)
+	return m.store.Save(states)
 }
 
 func (m *Memory) ClearState(accountID int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	file, err := os.OpenFile(m.StateFilePath, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 
-	var states []AccountState
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&states); err != nil && err.Error() != "EOF" {
+	states, err := m.store.Load()
+	if err != nil {
 		return err
 	}
 
@@ -224,14 +182,24 @@ func (m *Memory) ClearState(accountID int) error {
 		}
 	}
 
-	file.Seek(0, 0)
-	file.Truncate(0)
-	encoder := json.NewEncoder(file)
-	return encoder.Encode(updatedStates)
+	return m.store.Save(updatedStates)
+}
+
+// RewriteStates replaces all stored states with the result of fn.
+func (m *Memory) RewriteStates(fn func(states []AccountState) []AccountState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states, err := m.store.Load()
+	if err != nil {
+		return err
+	}
+
+	return m.store.Save(fn(states))
 }
 
 func (m *Memory) ClearAllStates() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return os.Truncate(m.StateFilePath, 0)
+	return m.store.Save(nil)
 }
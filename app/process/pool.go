@@ -0,0 +1,118 @@
+package process
+
+import (
+	"base/account"
+	"base/config"
+	"base/logger"
+	"base/utils"
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RunAccounts processes the accounts with at most MaxAccounts of them executing at once. An
+// account holds its slot while it works and gives it up while it waits for its next action, so
+// wallets scheduled days ahead do not keep the others from starting. Every account waits a
+// random delay from the start window after it gets its first slot, so wallets do not act in
+// lockstep. When ctx is done, accounts that have not started are skipped.
+func RunAccounts(ctx context.Context, accounts []*account.Account, cfg config.ConcurrencyConfig, process func(ctx context.Context, acc *account.Account, slot *Slot)) error {
+	if cfg.StartDelayMinSec < 0 || cfg.StartDelayMinSec > cfg.StartDelayMaxSec {
+		return errors.New("start_delay_min_sec должен быть неотрицательным и не больше start_delay_max_sec")
+	}
+
+	queue := make([]*account.Account, len(accounts))
+	copy(queue, accounts)
+	if cfg.Shuffle {
+		rand.Shuffle(len(queue), func(i, j int) { queue[i], queue[j] = queue[j], queue[i] })
+	}
+
+	slots := NewSlots(cfg.MaxAccounts)
+	var wg sync.WaitGroup
+	for _, acc := range queue {
+		slot := slots.Slot()
+		// accounts queue for their first slot in order, the semaphore wakes waiters first in first out
+		if err := slot.Acquire(ctx); err != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(acc *account.Account, slot *Slot) {
+			defer wg.Done()
+			defer slot.Release()
+
+			delay := startDelay(cfg)
+			if delay > 0 {
+				logger.GlobalLogger.Infof("Аккаунт %d начнет работу через %v.", acc.AccountID, delay)
+			}
+			if err := utils.Sleep(ctx, delay); err != nil {
+				return
+			}
+			process(ctx, acc, slot)
+		}(acc, slot)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// Slots limits how many accounts execute at once.
+type Slots struct {
+	sem chan struct{}
+}
+
+// NewSlots returns a limit of n accounts, n <= 0 means no limit.
+func NewSlots(n int) *Slots {
+	if n <= 0 {
+		return &Slots{}
+	}
+	return &Slots{sem: make(chan struct{}, n)}
+}
+
+// Slot is the claim of one account on Slots. It is used by that account's goroutine only.
+type Slot struct {
+	slots *Slots
+	held  bool
+}
+
+func (s *Slots) Slot() *Slot {
+	return &Slot{slots: s}
+}
+
+// Acquire waits for a free slot, it returns at once if the slot is already held. A nil slot
+// is never limited.
+func (s *Slot) Acquire(ctx context.Context) error {
+	if s == nil || s.held {
+		return nil
+	}
+	if s.slots.sem != nil {
+		select {
+		case s.slots.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.held = true
+	return nil
+}
+
+// Release gives the slot back, it does nothing if the slot is not held.
+func (s *Slot) Release() {
+	if s == nil || !s.held {
+		return
+	}
+	s.held = false
+	if s.slots.sem != nil {
+		<-s.slots.sem
+	}
+}
+
+func startDelay(cfg config.ConcurrencyConfig) time.Duration {
+	window := cfg.StartDelayMaxSec - cfg.StartDelayMinSec
+	seconds := cfg.StartDelayMinSec
+	if window > 0 {
+		seconds += rand.Intn(window + 1)
+	}
+	return time.Duration(seconds) * time.Second
+}
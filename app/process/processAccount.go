@@ -2,26 +2,36 @@ package process
 
 import (
 	"base/account"
+	"base/actions"
 	"base/actions/randomization"
 	"base/app/helpers"
 	"base/config"
 	"base/ethClient"
 	"base/logger"
 	"base/modules"
+	"base/utils"
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
-func ProcessAccount(acc *account.Account, accConfig *account.RandomConfig, mainConfig *config.Config, clients map[string]*ethClient.Client, randomizer *randomization.Randomizer, mods *modules.Modules, memory *Memory) {
+func ProcessAccount(ctx context.Context, acc *account.Account, accConfig *account.RandomConfig, mainConfig *config.Config, clients map[string]*ethClient.Client, randomizer *randomization.Randomizer, mods *modules.Modules, memory *Memory, retry actions.RetryPolicies, scheduler *helpers.Scheduler, slot *Slot, dryRun bool) {
 	if shouldBridge(acc) {
-		if err := bridgeToBase(acc, mainConfig, clients, mods); err != nil {
+		if err := bridgeToBase(ctx, acc, mainConfig, clients, mods, dryRun); err != nil {
 			logger.GlobalLogger.Warn(err)
 		}
 	}
+	if ctx.Err() != nil {
+		logger.GlobalLogger.Infof("Аккаунт %d остановлен до начала действий.", acc.AccountID)
+		return
+	}
 	logger.GlobalLogger.Infof("Начало обработки аккаунта %d.", acc.AccountID)
 
-	state, err := loadOrCreateState(acc, accConfig, randomizer, memory)
+	state, err := loadOrCreateState(ctx, acc, accConfig, randomizer, memory, scheduler, dryRun, mainConfig.StateConfig.RetryFailed)
 	if err != nil {
 		logger.GlobalLogger.Errorf("Ошибка с состоянием: %v", err)
 		return
@@ -30,7 +40,7 @@ func ProcessAccount(acc *account.Account, accConfig *account.RandomConfig, mainC
 	logger.GlobalLogger.Infof("Сгенерированная последовательность действий для аккаунта %d:\n%s",
 		acc.AccountID, helpers.FormatActionSequence(state.GeneratedActions, state.GeneratedIntervals))
 
-	executeActions(acc, state, mods, clients["base"], mainConfig, memory)
+	executeActions(ctx, acc, state, mods, clients["base"], mainConfig, memory, retry, slot, scheduler != nil, dryRun)
 	logger.GlobalLogger.Infof("Завершение обработки аккаунта %d.", acc.AccountID)
 }
 
@@ -38,15 +48,39 @@ func shouldBridge(acc *account.Account) bool {
 	return strings.TrimSpace(acc.Bridge) != "" && strings.TrimSpace(acc.TokenBridge) != ""
 }
 
-func loadOrCreateState(acc *account.Account, accConfig *account.RandomConfig, randomizer *randomization.Randomizer, memory *Memory) (*AccountState, error) {
+func loadOrCreateState(ctx context.Context, acc *account.Account, accConfig *account.RandomConfig, randomizer *randomization.Randomizer, memory *Memory, scheduler *helpers.Scheduler, dryRun, retryFailed bool) (*AccountState, error) {
 	state, err := memory.LoadState(acc.AccountID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка загрузки состояния для аккаунта %d: %w", acc.AccountID, err)
 	}
+	switch {
+	case state == nil:
+	case state.Address == "":
+		// saved before states recorded the wallet, it is taken as this wallet's from now on
+		state.Address = acc.Address.Hex()
+		if !dryRun {
+			if err := memory.SaveState(state); err != nil {
+				logger.GlobalLogger.Errorf("Ошибка сохранения состояния для аккаунта %d: %v", acc.AccountID, err)
+			}
+		}
+	case common.HexToAddress(state.Address) != acc.Address:
+		logger.GlobalLogger.Warnf("Состояние аккаунта %d принадлежит кошельку %s, а не %s: список кошельков изменился, план создается заново.", acc.AccountID, state.Address, acc.Address.Hex())
+		state = nil
+	}
 
 	if state != nil && len(state.GeneratedActions) > 0 {
+		if scheduler != nil && reschedule(state, scheduler, retryFailed) && !dryRun {
+			if err := memory.SaveState(state); err != nil {
+				logger.GlobalLogger.Errorf("Ошибка сохранения расписания для аккаунта %d: %v", acc.AccountID, err)
+			}
+		}
+
 		lastProcessedIndex := len(state.CompletedActions)
 		if lastProcessedIndex >= len(state.GeneratedActions) {
+			if retryFailed && state.HasFailed() {
+				logger.GlobalLogger.Infof("Аккаунт %d повторит неудачные действия.", acc.AccountID)
+				return state, nil
+			}
 			logger.GlobalLogger.Infof("Аккаунт %d уже выполнил все действия.", acc.AccountID)
 			return state, nil
 		}
@@ -54,19 +88,34 @@ func loadOrCreateState(acc *account.Account, accConfig *account.RandomConfig, ra
 		return state, nil
 	}
 
-	actionSequence, err := randomizer.GenerateActionSequence(&accConfig.Modules, &accConfig.Wallets[acc.AccountID-1], acc)
+	actionSequence, err := randomizer.GenerateActionSequence(ctx, accConfig.Modules, &accConfig.Wallets[acc.AccountID-1], acc)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации действий для аккаунта %d: %w", acc.AccountID, err)
 	}
 
-	totalDuration := helpers.GetRandomDuration(acc.ActionTimeMIN, acc.ActionTimeMAX)
-	intervals := helpers.DistributeActionsOverDuration(len(actionSequence), totalDuration)
-
 	state = &AccountState{
-		AccountID:          acc.AccountID,
-		GeneratedActions:   actionSequence,
-		GeneratedDuration:  totalDuration,
-		GeneratedIntervals: intervals,
+		AccountID:        acc.AccountID,
+		Address:          acc.Address.Hex(),
+		GeneratedActions: actionSequence,
+	}
+
+	switch {
+	case len(actionSequence) == 0:
+		logger.GlobalLogger.Infof("Аккаунт %d: нет действий для выполнения.", acc.AccountID)
+	case scheduler != nil:
+		now := time.Now()
+		state.ScheduledTimes = scheduler.Plan(len(actionSequence), now)
+		state.GeneratedIntervals = helpers.Intervals(now, state.ScheduledTimes)
+		state.GeneratedDuration = state.ScheduledTimes[len(state.ScheduledTimes)-1].Sub(now)
+		logger.GlobalLogger.Infof("Аккаунт %d: %d действий запланировано с %s по %s.", acc.AccountID, len(actionSequence),
+			state.ScheduledTimes[0].Format("2006-01-02 15:04"), state.ScheduledTimes[len(state.ScheduledTimes)-1].Format("2006-01-02 15:04"))
+	default:
+		state.GeneratedDuration = helpers.GetRandomDuration(acc.ActionTimeMIN, acc.ActionTimeMAX)
+		state.GeneratedIntervals = helpers.DistributeActionsOverDuration(len(actionSequence), state.GeneratedDuration)
+	}
+
+	if dryRun {
+		return state, nil
 	}
 
 	if err = memory.SaveState(state); err != nil {
@@ -76,36 +125,157 @@ func loadOrCreateState(acc *account.Account, accConfig *account.RandomConfig, ra
 	return state, nil
 }
 
-func executeActions(acc *account.Account, state *AccountState, mods *modules.Modules, client *ethClient.Client, mainConfig *config.Config, memory *Memory) {
-	actionsLeft := state.GeneratedActions[len(state.CompletedActions):]
-	intervalsLeft := state.GeneratedIntervals[len(state.CompletedActions):]
+// scheduleGrace is how late a planned action may start before the remaining ones are re-planned.
+const scheduleGrace = 15 * time.Minute
 
-	for i, action := range actionsLeft {
-		currentStepNumber := len(state.CompletedActions) + i + 1
+// reschedule plans the pending steps anew from now when the first of them is overdue, e.g. after
+// the program was stopped for a while, or when the state was created without a schedule.
+func reschedule(state *AccountState, scheduler *helpers.Scheduler, retryFailed bool) bool {
+	pending := state.Pending(retryFailed)
+	if len(pending) == 0 {
+		return false
+	}
 
-		logger.GlobalLogger.Infof("Аккаунт %d ждет %v перед началом действия %d.", acc.AccountID, intervalsLeft[i], currentStepNumber)
-		time.Sleep(intervalsLeft[i])
+	now := time.Now()
+	if len(state.ScheduledTimes) == len(state.GeneratedActions) && state.ScheduledTimes[pending[0]].After(now.Add(-scheduleGrace)) {
+		return false
+	}
 
-		logger.GlobalLogger.Infof("Аккаунт %d начинает действие: %s.", acc.AccountID, action.Type)
-		if err := action.TakeActions(*mods, acc, action, client, mainConfig); err != nil {
-			logger.GlobalLogger.Warnf("Ошибка выполнения (%s) для аккаунта %d: %v", action.Type, acc.AccountID, err)
-			if strings.Contains(err.Error(), "insufficient funds") {
-				if errRefuel := checkRefuel(acc, map[string]*ethClient.Client{"base": client}, mods); errRefuel != nil {
-					return
-				}
+	if len(state.ScheduledTimes) != len(state.GeneratedActions) {
+		state.ScheduledTimes = make([]time.Time, len(state.GeneratedActions))
+	}
+	for i, t := range scheduler.Plan(len(pending), now) {
+		state.ScheduledTimes[pending[i]] = t
+	}
+	logger.GlobalLogger.Infof("Аккаунт %d: расписание оставшихся %d действий пересчитано.", state.AccountID, len(pending))
+	return true
+}
+
+func executeActions(ctx context.Context, acc *account.Account, state *AccountState, mods *modules.Modules, client *ethClient.Client, mainConfig *config.Config, memory *Memory, retry actions.RetryPolicies, slot *Slot, scheduled, dryRun bool) {
+	retryFailed := mainConfig.StateConfig.RetryFailed
+
+	for index, action := range state.GeneratedActions {
+		var previous *ActionRecord
+		if index < len(state.CompletedActions) {
+			previous = &state.CompletedActions[index]
+			if !retryFailed || previous.Outcome != OutcomeFailed {
+				continue
 			}
+			logger.GlobalLogger.Infof("Аккаунт %d повторяет неудачное действие %d (%s): %s", acc.AccountID, index+1, action.Type, previous.Error)
+		}
+
+		interval := state.GeneratedIntervals[index]
+		wait := interval
+		if scheduled && index < len(state.ScheduledTimes) {
+			wait = time.Until(state.ScheduledTimes[index])
+		}
+
+		if dryRun {
+			logger.GlobalLogger.Infof("[DRY-RUN] Аккаунт %d пропускает ожидание %v перед действием %d.", acc.AccountID, wait.Round(time.Second), index+1)
 		} else {
+			if scheduled && index < len(state.ScheduledTimes) {
+				logger.GlobalLogger.Infof("Аккаунт %d ждет до %s перед началом действия %d.", acc.AccountID, state.ScheduledTimes[index].Local().Format("2006-01-02 15:04"), index+1)
+			} else {
+				logger.GlobalLogger.Infof("Аккаунт %d ждет %v перед началом действия %d.", acc.AccountID, interval, index+1)
+			}
+			// a waiting account does not count against max_accounts
+			slot.Release()
+			if err := utils.Sleep(ctx, wait); err != nil {
+				logger.GlobalLogger.Infof("Аккаунт %d остановлен, действие %d не начато, состояние сохранено.", acc.AccountID, index+1)
+				return
+			}
+			if err := slot.Acquire(ctx); err != nil {
+				logger.GlobalLogger.Infof("Аккаунт %d остановлен, действие %d не начато, состояние сохранено.", acc.AccountID, index+1)
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			logger.GlobalLogger.Infof("Аккаунт %d остановлен, действие %d не начато, состояние сохранено.", acc.AccountID, index+1)
+			return
+		}
+
+		logger.GlobalLogger.Infof("Аккаунт %d начинает действие: %s.", acc.AccountID, action.Type)
+		acc.SetCurrentAction(string(action.Type), actions.ModuleName(action.Type))
+		acc.TakeTxs()
+
+		refuel := func() error {
+			err := checkRefuel(ctx, acc, map[string]*ethClient.Client{"base": client}, mods)
+			acc.SetCurrentAction(string(action.Type), actions.ModuleName(action.Type))
+			return err
+		}
+		attempts, err := action.TakeActionsWithRetry(ctx, *mods, acc, client, mainConfig, retry.For(action.Type), refuel)
+		hashes := acc.TakeTxs()
+
+		// interrupted before anything was broadcast: leave the step for the next run
+		if err != nil && ctx.Err() != nil && len(hashes) == 0 {
+			logger.GlobalLogger.Infof("Аккаунт %d остановлен во время действия %d (%s), оно будет выполнено при следующем запуске.", acc.AccountID, index+1, action.Type)
+			return
+		}
+
+		record := newActionRecord(action, err, attempts, hashes, previous)
+
+		switch record.Outcome {
+		case OutcomeSuccess:
 			logger.GlobalLogger.Infof("Действие (%s) для аккаунта %d выполнено успешно.", action.Type, acc.AccountID)
+		case OutcomeSkipped:
+			logger.GlobalLogger.Infof("Действие (%s) для аккаунта %d пропущено: %v", action.Type, acc.AccountID, err)
+		default:
+			logger.GlobalLogger.Warnf("Ошибка выполнения (%s) для аккаунта %d после %d попыток (%s): %v", action.Type, acc.AccountID, attempts, actions.ClassifyError(err), err)
+			if errors.Is(err, actions.ErrRefuelFailed) {
+				return
+			}
+		}
+
+		if dryRun {
+			continue
 		}
 
-		if err := memory.UpdateState(acc.AccountID, action, intervalsLeft[i]); err != nil {
+		if index < len(state.CompletedActions) {
+			state.CompletedActions[index] = record
+		} else {
+			state.CompletedActions = append(state.CompletedActions, record)
+		}
+		if err := memory.UpdateState(acc.AccountID, index, record, interval); err != nil {
 			logger.GlobalLogger.Errorf("Ошибка обновления состояния аккаунта %d: %v", acc.AccountID, err)
 		}
 	}
 
+	if dryRun {
+		return
+	}
+
+	if retryFailed && state.HasFailed() {
+		logger.GlobalLogger.Warnf("У аккаунта %d остались неудачные действия, состояние сохранено для повтора.", acc.AccountID)
+		return
+	}
+
 	if err := memory.ClearState(acc.AccountID); err != nil {
 		logger.GlobalLogger.Errorf("Ошибка очистки состояния для аккаунта %d: %v", acc.AccountID, err)
 	}
-	logger.GlobalLogger.Infof("Анализ аккаунтов...")
-	// client.Analizor()
+}
+
+func newActionRecord(action actions.Action, err error, attempts int, hashes []common.Hash, previous *ActionRecord) ActionRecord {
+	record := ActionRecord{
+		Action:     action,
+		Outcome:    OutcomeSuccess,
+		Attempts:   attempts,
+		FinishedAt: time.Now(),
+	}
+	if previous != nil {
+		record.Attempts += previous.Attempts
+		record.TxHashes = append(record.TxHashes, previous.TxHashes...)
+	}
+	for _, hash := range hashes {
+		record.TxHashes = append(record.TxHashes, hash.Hex())
+	}
+
+	if err != nil {
+		record.Outcome = OutcomeFailed
+		if errors.Is(err, actions.ErrSkipped) {
+			record.Outcome = OutcomeSkipped
+		}
+		record.Error = err.Error()
+	}
+
+	return record
 }
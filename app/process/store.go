@@ -0,0 +1,190 @@
+package process
+
+import (
+	"base/actions"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// StateSchemaVersion is bumped whenever AccountState changes in a way older files need migrating for.
+const StateSchemaVersion = 4
+
+type StateStore interface {
+	Load() ([]AccountState, error)
+	Save(states []AccountState) error
+}
+
+type stateFile struct {
+	Version  int            `json:"version"`
+	Accounts []AccountState `json:"accounts"`
+}
+
+// FileStateStore keeps all account states in one JSON file. Every save goes to a temporary file
+// that is synced and renamed over the old one, so a crash leaves either the old or the new state.
+type FileStateStore struct {
+	Path string
+}
+
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{Path: path}
+}
+
+func (s *FileStateStore) Load() ([]AccountState, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла состояния: %v", err)
+	}
+
+	states, version, err := decodeState(data)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка декодирования файла состояния: %v", err)
+	}
+
+	// migrated in memory only: the file keeps the old format, readable by older builds, until
+	// the next save
+	if version < StateSchemaVersion {
+		states = migrateState(states, version)
+	}
+
+	return states, nil
+}
+
+func (s *FileStateStore) Save(states []AccountState) error {
+	if states == nil {
+		states = []AccountState{}
+	}
+
+	data, err := json.MarshalIndent(stateFile{Version: StateSchemaVersion, Accounts: states}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.Path, data)
+}
+
+func decodeState(data []byte) ([]AccountState, int, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, StateSchemaVersion, nil
+	}
+
+	// version 1 was a bare array of states
+	if data[0] == '[' {
+		var states []legacyAccountState
+		if err := json.Unmarshal(data, &states); err != nil {
+			return nil, 0, err
+		}
+		return migrateLegacy(states), 1, nil
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, 0, err
+	}
+	if header.Version > StateSchemaVersion {
+		return nil, 0, fmt.Errorf("версия файла состояния %d новее поддерживаемой %d", header.Version, StateSchemaVersion)
+	}
+
+	if header.Version < 3 {
+		var file struct {
+			Accounts []legacyAccountState `json:"accounts"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, 0, err
+		}
+		return migrateLegacy(file.Accounts), header.Version, nil
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, 0, err
+	}
+	return file.Accounts, file.Version, nil
+}
+
+// legacyAccountState is the layout of versions 1 and 2, where completed steps were stored as
+// bare actions without an outcome.
+type legacyAccountState struct {
+	AccountState
+	CompletedActions []actions.Action `json:"completed_actions"`
+}
+
+func migrateLegacy(legacy []legacyAccountState) []AccountState {
+	states := make([]AccountState, 0, len(legacy))
+	for _, l := range legacy {
+		state := l.AccountState
+		state.CompletedActions = make([]ActionRecord, 0, len(l.CompletedActions))
+		for _, action := range l.CompletedActions {
+			state.CompletedActions = append(state.CompletedActions, ActionRecord{
+				Action:   action,
+				Outcome:  OutcomeUnknown,
+				Attempts: 1,
+			})
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+func migrateState(states []AccountState, from int) []AccountState {
+	switch from {
+	case 1:
+		// 1 -> 2: the bare array got wrapped into a versioned envelope, entries are unchanged
+		fallthrough
+	case 2:
+		// 2 -> 3: completed steps became ActionRecord, converted while decoding
+		fallthrough
+	case 3:
+		// 3 -> 4: actions are stored as {"type", "params"}, the old layout is read by
+		// Action.UnmarshalJSON and written back in the new one
+	}
+	return states
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		// a file bind-mounted into a container can not be replaced
+		if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf("файл состояния %s нельзя заменить, похоже, он смонтирован отдельным файлом: смонтируйте каталог с ним: %w", path, err)
+		}
+		return err
+	}
+
+	// persist the rename itself
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -1,30 +1,119 @@
 package process
 
 import (
+	"base/account"
 	"base/logger"
 	"fmt"
+	"os"
+	"strings"
 )
 
-func UploadOldAction(memoryHandler *Memory) {
-	stateExists, err := memoryHandler.IsStateFileNotEmpty()
+const (
+	ResumeAsk        = "ask"
+	ResumeContinue   = "resume"
+	ResumeFresh      = "fresh"
+	ResumeFailedOnly = "resume_failed_only"
+)
+
+// ResumeOptions decides what happens to the saved state at startup.
+type ResumeOptions struct {
+	Policy string
+	// AccountIDs limits resuming to these accounts, the state of the others is cleared.
+	// Empty means all accounts.
+	AccountIDs map[int]bool
+}
+
+// ApplyResumePolicy prepares the state file for the run. With "ask" the user is prompted when
+// stdin is a terminal, otherwise (docker, cron) the state is resumed.
+func ApplyResumePolicy(memory *Memory, opts ResumeOptions) error {
+	switch opts.Policy {
+	case ResumeAsk, ResumeContinue, ResumeFresh, ResumeFailedOnly:
+	default:
+		return fmt.Errorf("неизвестная политика продолжения %q", opts.Policy)
+	}
+
+	stateExists, err := memory.IsStateFileNotEmpty()
 	if err != nil {
-		logger.GlobalLogger.Errorf("Ошибка проверки состояния: %v", err)
-		return
+		return fmt.Errorf("ошибка проверки состояния: %v", err)
+	}
+	if !stateExists {
+		logger.GlobalLogger.Info("Файл состояния пуст. Начинаем выполнение с чистого листа.")
+		return nil
+	}
+
+	policy := opts.Policy
+	if policy == ResumeAsk {
+		policy = askResumePolicy()
 	}
 
-	if stateExists {
-		var userInput string
-		fmt.Println("Продолжить выполнение? (y/n): ")
-		fmt.Scanln(&userInput)
+	switch policy {
+	case ResumeFresh:
+		logger.GlobalLogger.Info("Сохраненное состояние очищено, начинаем с чистого листа.")
+		return memory.ClearAllStates()
+	case ResumeFailedOnly:
+		logger.GlobalLogger.Info("Продолжаем только неудачные действия.")
+	default:
+		logger.GlobalLogger.Info("Продолжаем выполнение из сохраненного состояния.")
+	}
 
-		if userInput != "y" {
-			err := memoryHandler.ClearAllStates()
-			if err != nil {
-				logger.GlobalLogger.Errorf("Ошибка очистки состояния: %v", err)
-				return
+	return memory.RewriteStates(func(states []AccountState) []AccountState {
+		kept := make([]AccountState, 0, len(states))
+		for _, state := range states {
+			if len(opts.AccountIDs) > 0 && !opts.AccountIDs[state.AccountID] {
+				continue
 			}
+			if policy == ResumeFailedOnly {
+				if !state.HasFailed() {
+					continue
+				}
+				dropUnstarted(&state)
+			}
+			kept = append(kept, state)
 		}
-	} else {
-		logger.GlobalLogger.Info("Файл состояния пуст. Начинаем выполнение с чистого листа.")
+		return kept
+	})
+}
+
+// WithState returns the accounts that have a saved state, used when only failed steps are re-run
+// and accounts without them have nothing to do.
+func WithState(memory *Memory, accounts []*account.Account) ([]*account.Account, error) {
+	var kept []*account.Account
+	for _, acc := range accounts {
+		state, err := memory.LoadState(acc.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			kept = append(kept, acc)
+		}
+	}
+	return kept, nil
+}
+
+// dropUnstarted removes the steps that never ran, so only recorded ones remain to be retried.
+func dropUnstarted(state *AccountState) {
+	n := len(state.CompletedActions)
+	state.GeneratedActions = state.GeneratedActions[:n]
+	if len(state.GeneratedIntervals) > n {
+		state.GeneratedIntervals = state.GeneratedIntervals[:n]
+	}
+	if len(state.ScheduledTimes) > n {
+		state.ScheduledTimes = state.ScheduledTimes[:n]
+	}
+}
+
+func askResumePolicy() string {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		logger.GlobalLogger.Warn("Нет терминала для вопроса о продолжении, продолжаем сохраненное состояние. Используйте --resume или --fresh.")
+		return ResumeContinue
+	}
+
+	var userInput string
+	fmt.Println("Продолжить выполнение? (y/n): ")
+	fmt.Scanln(&userInput)
+
+	if strings.TrimSpace(userInput) != "y" {
+		return ResumeFresh
 	}
+	return ResumeContinue
 }
@@ -0,0 +1,515 @@
+package validate
+
+import (
+	"base/account"
+	"base/actions"
+	"base/app/helpers"
+	"base/config"
+	"base/ethClient"
+	"base/httpClient"
+	"base/utils"
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const codeCheckTimeout = 15 * time.Second
+
+// Problem is a single mistake found in the configuration. Section points at the place in the
+// files, e.g. "config.dex.woofi.ca" or "account_config.wallets[2]".
+type Problem struct {
+	Section string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Section + ": " + p.Message
+}
+
+// abiMethods lists the methods each module packs from its ABI file.
+var abiMethods = []struct {
+	section string
+	path    func(cfg *config.Config) string
+	methods []string
+}{
+	{"config.dex.pancake.router_abi_path", func(cfg *config.Config) string { return cfg.DexConfig.Pancake.RouterABIPath }, []string{"exactInputSingle", "multicall", "unwrapWETH9"}},
+	{"config.dex.pancake.quoter_abi_path", func(cfg *config.Config) string { return cfg.DexConfig.Pancake.QuoterABIPath }, []string{"quoteExactInputSingle"}},
+	{"config.dex.woofi.abi_path", func(cfg *config.Config) string { return cfg.DexConfig.Woofi.ABIPath }, []string{"swap", "tryQuerySwap"}},
+	{"config.refuel.api_path", func(cfg *config.Config) string { return cfg.RefuelConfig.ABIPath }, []string{"depositNativeToken"}},
+	{"config.bridge.swap_abi_path", func(cfg *config.Config) string { return cfg.BridgeConfig.SwapABIPath }, []string{"swap"}},
+	{"config.bridge.fee_abi_path", func(cfg *config.Config) string { return cfg.BridgeConfig.FeeABIPath }, []string{"quoteLayerZeroFee"}},
+	{"config.domains.register_abi_path", func(cfg *config.Config) string { return cfg.DomainsConfig.RegisterABIPath }, []string{"register"}},
+	{"config.domains.resolver_abi_path", func(cfg *config.Config) string { return cfg.DomainsConfig.ResolverABIPath }, []string{"setAddr", "setName", "setText"}},
+	{"config.dmail.abi_path", func(cfg *config.Config) string { return cfg.DmailConfig.ABIPath }, []string{"send_mail"}},
+	{"config.liquid_pools.aave.abi_path", func(cfg *config.Config) string { return cfg.LiquidPoolsConfig.Aave.ABIPath }, []string{"depositETH", "withdrawETH", "supply", "withdraw"}},
+	{"config.liquid_pools.moonwell.abi_path", func(cfg *config.Config) string { return cfg.LiquidPoolsConfig.Moonwell.ABIPath }, []string{"mint"}},
+	{"config.liquid_pools.moonwell.mweth_abi_path", func(cfg *config.Config) string { return cfg.LiquidPoolsConfig.Moonwell.MWethABIPath }, []string{"redeem"}},
+	{"config.nft_mints.zora.abi_path", func(cfg *config.Config) string { return cfg.NFTMintsConfig.Zora.ABIPath }, []string{"buy1155"}},
+	{"config.nft_mints.nft2me.abi_path", func(cfg *config.Config) string { return cfg.NFTMintsConfig.NFT2Me.ABIPath }, []string{"mint"}},
+}
+
+type validator struct {
+	ctx            context.Context
+	cfg            *config.Config
+	accConfig      *account.RandomConfig
+	keystorePath   string
+	keystoreExists bool
+	clients        map[string]*ethClient.Client
+	noClient       map[string]bool
+	problems       []Problem
+}
+
+// Run checks the account config and the main config end-to-end and returns every problem it
+// finds instead of stopping at the first one. Contract addresses are checked for code through
+// the configured RPCs; nothing is sent and no state or journal file is touched. Keys in the
+// keystore are not decrypted, only the references to them are checked.
+func Run(ctx context.Context, accConfigPath, configPath, keystorePath string) []Problem {
+	_, err := os.Stat(keystorePath)
+	v := &validator{
+		ctx:            ctx,
+		keystorePath:   keystorePath,
+		keystoreExists: err == nil,
+		clients:        make(map[string]*ethClient.Client),
+		noClient:       make(map[string]bool),
+	}
+	defer ethClient.CloseAllClients(v.clients)
+
+	accConfig, err := account.LoadRandomConfig(accConfigPath)
+	if err != nil || accConfig == nil {
+		v.add("account_config", "не удалось прочитать %s: %v", accConfigPath, err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		v.add("config", "не удалось прочитать %s: %v", configPath, err)
+	}
+	if v.problems != nil {
+		return v.problems
+	}
+	v.cfg, v.accConfig = cfg, accConfig
+
+	v.checkABIs()
+	v.checkSettings()
+	v.checkTokens()
+	v.checkWallets()
+	v.checkModules()
+
+	return v.problems
+}
+
+// add skips a problem that is already reported, the wallets of a mnemonic entry share their
+// settings and would repeat it once per wallet.
+func (v *validator) add(section, format string, args ...any) {
+	problem := Problem{Section: section, Message: fmt.Sprintf(format, args...)}
+	for _, p := range v.problems {
+		if p == problem {
+			return
+		}
+	}
+	v.problems = append(v.problems, problem)
+}
+
+// Every module's ABI is read at start-up, so the files are checked whether the module is
+// enabled or not.
+func (v *validator) checkABIs() {
+	for _, file := range abiMethods {
+		path := file.path(v.cfg)
+		if path == "" {
+			v.add(file.section, "путь к ABI не задан")
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(utils.GetRootDir(), path))
+		if err != nil {
+			v.add(file.section, "не удалось прочитать ABI: %v", err)
+			continue
+		}
+		parsed, err := abi.JSON(strings.NewReader(string(data)))
+		if err != nil {
+			v.add(file.section, "ABI %s не разбирается: %v", path, err)
+			continue
+		}
+
+		for _, method := range file.methods {
+			if _, ok := parsed.Methods[method]; !ok {
+				v.add(file.section, "в ABI %s нет метода %s", path, method)
+			}
+		}
+	}
+}
+
+func (v *validator) checkSettings() {
+	if _, err := actions.NewRetryPolicies(v.cfg.RetryConfig); err != nil {
+		v.add("config.retry", "%v", err)
+	}
+
+	for _, chain := range sortedKeys(v.cfg.GasConfig) {
+		if _, err := ethClient.NewGasStrategy(v.cfg.GasConfig[chain]); err != nil {
+			v.add("config.gas."+chain, "%v", err)
+		}
+	}
+
+	if v.cfg.ScheduleConfig.Enabled {
+		if _, err := helpers.NewScheduler(v.cfg.ScheduleConfig); err != nil {
+			v.add("config.schedule", "%v", err)
+		}
+	}
+
+	concurrency := v.cfg.ConcurrencyConfig
+	if concurrency.StartDelayMinSec < 0 || concurrency.StartDelayMinSec > concurrency.StartDelayMaxSec {
+		v.add("config.concurrency", "start_delay_min_sec должен быть неотрицательным и не больше start_delay_max_sec")
+	}
+
+	if proxy := v.cfg.Chains.Proxy; proxy != "" {
+		if _, err := httpClient.ParseProxy(proxy); err != nil {
+			v.add("config.proxy", "%v", err)
+		}
+	}
+	if proxies := v.cfg.ProxyConfig; proxies.CheckIntervalSec < 0 || proxies.CooldownSec < 0 {
+		v.add("config.proxies", "check_interval_sec и cooldown_sec не могут быть отрицательными")
+	}
+
+	for _, key := range sortedKeys(v.cfg.PriceConfig.Chainlink) {
+		v.checkContract("config.prices.chainlink."+key, "base", v.cfg.PriceConfig.Chainlink[key])
+	}
+}
+
+func (v *validator) checkTokens() {
+	base, _ := v.cfg.Chains.Chain("base")
+	for _, name := range v.cfg.SwapTokenNames() {
+		if _, ok := base.Token(name); !ok {
+			v.add("config.swap_tokens", "токен %s не задан в chains.base.tokens", name)
+		}
+	}
+
+	for _, name := range sortedKeys(base.Tokens) {
+		v.checkContract("config.chains.base.tokens."+name, "base", base.Tokens[name].Hex())
+	}
+}
+
+func (v *validator) checkWallets() {
+	if len(v.accConfig.Wallets) == 0 {
+		v.add("account_config.wallets", "нет ни одного кошелька")
+	}
+
+	// mnemonic entries are checked wallet by wallet, and the modules are checked against the
+	// settings the wallets end up with after their overrides
+	var expanded []account.WalletConfig
+	for i, entry := range v.accConfig.Wallets {
+		section := fmt.Sprintf("account_config.wallets[%d]", i+1)
+
+		wallets, err := entry.Expand()
+		if err != nil {
+			v.add(section, "%v", err)
+			expanded = append(expanded, entry)
+			continue
+		}
+		for _, wallet := range wallets {
+			v.checkWallet(section, wallet)
+		}
+		expanded = append(expanded, wallets...)
+	}
+	v.accConfig.Wallets = expanded
+}
+
+func (v *validator) checkWallet(section string, wallet account.WalletConfig) {
+	switch {
+	case wallet.PrivateKey != "":
+		if _, err := utils.ParsePrivateKey(wallet.PrivateKey); err != nil {
+			v.add(section, "некорректный приватный ключ: %v", err)
+		}
+	case wallet.Mnemonic != "":
+		if common.IsHexAddress(wallet.Address) {
+			v.checkDerivedAddress(section, wallet)
+		}
+	case wallet.KeyRef() == "":
+		v.add(section, "не задан ни private_key, ни address или label")
+	case !v.keystoreExists:
+		v.add(section, "кошелек указан как %s, но хранилища ключей %s нет", wallet.KeyRef(), v.keystorePath)
+	}
+	if wallet.Address != "" && !common.IsHexAddress(wallet.Address) {
+		v.add(section, "некорректный address: %s", wallet.Address)
+	}
+	if wallet.Endpoint != "" && !common.IsHexAddress(wallet.Endpoint) {
+		v.add(section, "некорректный адрес endpoint: %s", wallet.Endpoint)
+	}
+	for _, proxy := range wallet.ProxyList() {
+		if _, err := httpClient.ParseProxy(proxy); err != nil {
+			v.add(section, "%v", err)
+		}
+	}
+	if wallet.UsedRange < 0 || wallet.UsedRange > 100 || wallet.PoolUsedRange < 0 || wallet.PoolUsedRange > 100 {
+		v.add(section, "used_range и used_range_in_pools задаются в процентах от 0 до 100")
+	}
+	if (wallet.ActionNumMIN == nil) != (wallet.ActionNumMAX == nil) {
+		v.add(section, "action_num_min и action_num_max задаются только вместе")
+	} else if wallet.ActionNumMIN != nil && (*wallet.ActionNumMIN < 1 || *wallet.ActionNumMIN > *wallet.ActionNumMAX) {
+		v.add(section, "action_num_min должен быть не меньше 1 и не больше action_num_max")
+	}
+	if (wallet.ActionTimeMIN == nil) != (wallet.ActionTimeMAX == nil) {
+		v.add(section, "action_time_window_MIN и action_time_window_MAX задаются только вместе")
+	} else if wallet.ActionTimeMIN != nil && (*wallet.ActionTimeMIN < 0 || *wallet.ActionTimeMIN > *wallet.ActionTimeMAX) {
+		v.add(section, "action_time_window_MIN должен быть неотрицательным и не больше action_time_window_MAX")
+	}
+
+	v.checkWalletBridge(section, wallet)
+}
+
+// checkDerivedAddress compares the address set in an override with the key derived for it.
+func (v *validator) checkDerivedAddress(section string, wallet account.WalletConfig) {
+	path, err := accounts.ParseDerivationPath(wallet.DerivationPath)
+	if err != nil {
+		v.add(section, "некорректный derivation_path %s: %v", wallet.DerivationPath, err)
+		return
+	}
+	key, err := utils.DeriveHDKey(wallet.Mnemonic, wallet.MnemonicPassphrase, path)
+	if err != nil {
+		v.add(section, "%s: %v", wallet.DerivationPath, err)
+		return
+	}
+	if address := utils.DeriveAddress(key); address != common.HexToAddress(wallet.Address) {
+		v.add(section, "ключ %s принадлежит адресу %s, а не %s", wallet.DerivationPath, address.Hex(), wallet.Address)
+	}
+}
+
+func (v *validator) checkWalletBridge(section string, wallet account.WalletConfig) {
+	chain, token := strings.TrimSpace(wallet.Bridge), strings.TrimSpace(wallet.Token)
+	switch {
+	case chain == "" && token == "":
+		return
+	case chain == "" || token == "":
+		v.add(section, "для депозита нужно указать и bridge, и token")
+		return
+	}
+
+	if c, ok := v.cfg.Chains.Chain(chain); !ok || c.StargateRouter == (common.Address{}) {
+		v.add(section, "бридж из сети %s не поддерживается", chain)
+	}
+	if _, ok := v.cfg.Chains.Token(chain, token); !ok {
+		v.add(section, "токен %s не задан в chains.%s.tokens", token, chain)
+	}
+	if len(v.cfg.RPCsFor(chain)) == 0 {
+		v.add(section, "нет RPC для сети %s", chain)
+	}
+}
+
+func (v *validator) checkModules() {
+	modules := v.accConfig.Modules
+	for _, key := range actions.UnknownModules(modules) {
+		v.add("account_config.modules."+key, "неизвестный модуль")
+	}
+
+	dex := v.cfg.DexConfig
+	// the Uniswap quoter also prices tokens and the collector swaps through Uniswap
+	if modules.Enabled("uniswap") || modules.Enabled("collector_mod") || v.quoterPrices() {
+		v.checkContract("config.dex.uniswap.router_ca", "base", dex.Uniswap.RouterCA)
+		v.checkContract("config.dex.uniswap.quoter_ca", "base", dex.Uniswap.QuoterCA)
+	}
+	if modules.Enabled("pancake") {
+		v.checkContract("config.dex.pancake.router_ca", "base", dex.Pancake.RouterCA)
+		v.checkContract("config.dex.pancake.quoter_ca", "base", dex.Pancake.QuoterCA)
+	}
+	if modules.Enabled("woofi") {
+		v.checkContract("config.dex.woofi.ca", "base", dex.Woofi.CA)
+	}
+	if modules.Enabled("odos") {
+		v.checkContract("config.dex.odos.ca", "base", dex.Odos.CA)
+	}
+	if modules.Enabled("openocean") {
+		v.checkContract("config.dex.openocean.ca", "base", dex.OpenOcean.CA)
+	}
+
+	if modules.Enabled("refuel") {
+		refuel := v.cfg.RefuelConfig
+		v.checkContract("config.refuel.optimism_socket", "optimism", refuel.OptimismSocket)
+		v.checkContract("config.refuel.arbirum_socket", "arbitrum", refuel.ArbitrumSocket)
+		v.checkContract("config.refuel.avalanche_socket", "avalanche", refuel.AvalancheSocket)
+		v.checkContract("config.refuel.polygon_socket", "polygon", refuel.PolygonSocket)
+		v.checkContract("config.refuel.base_socket", "base", refuel.BaseSocket)
+	}
+
+	v.checkBridge()
+
+	if modules.Enabled("dmail") {
+		v.checkContract("config.dmail.ca", "base", v.cfg.DmailConfig.CA)
+	}
+
+	if modules.Enabled("basenames") {
+		v.checkContract("config.domains.register_ca", "base", v.cfg.DomainsConfig.RegisterCA)
+		v.checkContract("config.domains.resolver_ca", "base", v.cfg.DomainsConfig.ResolverCA)
+		if !v.anyWallet(func(wallet account.WalletConfig) bool { return strings.TrimSpace(wallet.BaseName) != "" }) {
+			v.add("account_config.modules.basenames", "модуль включен, но ни у одного кошелька не задан base_name")
+		}
+	}
+
+	pools := v.cfg.LiquidPoolsConfig
+	if modules.Enabled("aave") || modules.Enabled("collector_mod") {
+		v.checkContract("config.liquid_pools.aave.proxy_base", "base", pools.Aave.ProxyBase)
+		v.checkContract("config.liquid_pools.aave.eth_pool", "base", pools.Aave.EthPool)
+	}
+	if modules.Enabled("moonwell") || modules.Enabled("collector_mod") {
+		v.checkContract("config.liquid_pools.moonwell.weth_router", "base", pools.Moonwell.CA)
+		v.checkContract("config.liquid_pools.moonwell.meth_ca", "base", pools.Moonwell.METHCA)
+	}
+
+	if modules.Enabled("zora") {
+		v.checkContract("config.nft_mints.zora.ca", "base", v.cfg.NFTMintsConfig.Zora.CA)
+		v.checkNFTs("account_config.nft_ca.zora", v.accConfig.NFTContracts.Zora)
+	}
+	if modules.Enabled("nft2me") {
+		v.checkNFTs("account_config.nft_ca.nf2me", v.accConfig.NFTContracts.Nft2Me)
+	}
+}
+
+// checkBridge checks the Stargate contracts of every chain a wallet bridges from, or of every
+// configured chain when the stargate module is enabled.
+func (v *validator) checkBridge() {
+	chains := make(map[string]bool)
+	for _, wallet := range v.accConfig.Wallets {
+		if chain := strings.TrimSpace(wallet.Bridge); chain != "" {
+			chains[chain] = true
+		}
+	}
+	if v.accConfig.Modules.Enabled("stargate") {
+		for _, name := range v.cfg.Chains.Names() {
+			if chain, _ := v.cfg.Chains.Chain(name); chain.StargateRouter != (common.Address{}) {
+				chains[name] = true
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(chains) {
+		chain, ok := v.cfg.Chains.Chain(name)
+		if !ok {
+			// reported with the wallet
+			continue
+		}
+		v.checkContract("config.chains."+name+".stargate.swap_ca", name, addressOrEmpty(chain.StargateRouter))
+		v.checkContract("config.chains."+name+".stargate.fee_ca", name, addressOrEmpty(chain.StargateFeeQuoter))
+	}
+}
+
+func (v *validator) checkNFTs(section string, contracts map[string]string) {
+	if len(contracts) == 0 {
+		v.add(section, "модуль включен, но контракты для минта не заданы")
+		return
+	}
+	for _, addr := range sortedKeys(contracts) {
+		if _, ok := new(big.Float).SetString(contracts[addr]); !ok {
+			v.add(section+"."+addr, "некорректная цена: %s", contracts[addr])
+		}
+		v.checkContract(section+"."+addr, "base", addr)
+	}
+}
+
+// checkContract reports an address that is missing, malformed, zero or has no code on the chain.
+// Chains without an RPC are only checked offline.
+func (v *validator) checkContract(section, chain, addr string) {
+	switch {
+	case strings.TrimSpace(addr) == "":
+		v.add(section, "адрес не задан")
+		return
+	case !common.IsHexAddress(addr):
+		v.add(section, "некорректный адрес: %s", addr)
+		return
+	}
+	address := common.HexToAddress(addr)
+	switch address {
+	case common.Address{}:
+		v.add(section, "нулевой адрес")
+		return
+	case v.cfg.Chains.Base.ETH:
+		// the native token placeholder has no code
+		return
+	}
+
+	client := v.client(chain)
+	if client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(v.ctx, codeCheckTimeout)
+	defer cancel()
+	code, err := client.Client.CodeAt(ctx, address, nil)
+	switch {
+	case err != nil && v.ctx.Err() == nil:
+		// one unreachable RPC would otherwise be reported for every address on the chain
+		v.add("config.rpcs."+client.Chain, "коды контрактов не проверены, RPC недоступны: %v", err)
+		delete(v.clients, client.Chain)
+		v.noClient[client.Chain] = true
+		client.Pool.Close()
+		client.Client.Close()
+	case err == nil && len(code) == 0:
+		v.add(section, "по адресу %s в сети %s нет контракта", address.Hex(), chain)
+	}
+}
+
+// client returns an RPC client for the chain, or nil when the chain has no RPC. Clients are
+// created once and a failure is reported once per chain.
+func (v *validator) client(chain string) *ethClient.Client {
+	if client, ok := v.clients[chain]; ok {
+		return client
+	}
+	if v.noClient[chain] {
+		return nil
+	}
+
+	rpcs := v.cfg.RPCsFor(chain)
+	if len(rpcs) == 0 {
+		v.noClient[chain] = true
+		return nil
+	}
+	client, err := ethClient.NewClient(chain, rpcs)
+	if err != nil {
+		v.add("config.rpcs."+chain, "не удалось подключиться: %v", err)
+		v.noClient[chain] = true
+		return nil
+	}
+	v.clients[chain] = client
+	return client
+}
+
+func (v *validator) quoterPrices() bool {
+	if len(v.cfg.PriceConfig.Sources) == 0 {
+		return true
+	}
+	for _, source := range v.cfg.PriceConfig.Sources {
+		if strings.EqualFold(source, "quoter") {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) anyWallet(match func(wallet account.WalletConfig) bool) bool {
+	for _, wallet := range v.accConfig.Wallets {
+		if match(wallet) {
+			return true
+		}
+	}
+	return false
+}
+
+func addressOrEmpty(addr common.Address) string {
+	if addr == (common.Address{}) {
+		return ""
+	}
+	return addr.Hex()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
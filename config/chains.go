@@ -0,0 +1,356 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// chains.json holds the built-in chain and protocol data. The chains and proxy sections of
+// config.json are merged over it, so a config only lists what it changes.
+//
+//go:embed chains.json
+var defaultChainsJSON []byte
+
+// Chains is the registry the software runs with. It starts with the embedded defaults and is
+// replaced by the one built from the loaded config at start-up.
+var Chains *ChainRegistry
+
+// ChainsConfig is the layout shared by chains.json and the chains section of config.json.
+type ChainsConfig struct {
+	Proxy  string                 `json:"proxy"`
+	Chains map[string]ChainConfig `json:"chains"`
+}
+
+type ChainConfig struct {
+	ChainID  int64             `json:"chain_id"`
+	RPCs     []string          `json:"rpcs"`
+	Tokens   map[string]string `json:"tokens"` // name -> address
+	Stargate StargateConfig    `json:"stargate"`
+	// protocol contracts whose approvals the collector revokes, by token name
+	ApprovalSpenders map[string][]string `json:"approval_spenders"`
+	ChainlinkFeeds   map[string]string   `json:"chainlink_feeds"`     // token name -> USD aggregator
+	FallbackPrices   map[string]float64  `json:"fallback_prices_usd"` // token name -> USD
+}
+
+type StargateConfig struct {
+	SwapCA string `json:"swap_ca"`
+	FeeCA  string `json:"fee_ca"`
+}
+
+// Chain is the typed data of one network.
+type Chain struct {
+	Name              string
+	ID                int64
+	RPCs              []string
+	Tokens            map[string]common.Address // lower-case name -> address
+	StargateRouter    common.Address            // zero when Stargate is not deployed
+	StargateFeeQuoter common.Address
+}
+
+// Token returns the address of a token on the chain by its name, e.g. "usdc".
+func (c *Chain) Token(name string) (common.Address, bool) {
+	addr, ok := c.Tokens[strings.ToLower(name)]
+	return addr, ok
+}
+
+// BaseTokens are the Base tokens that modules use by role rather than by name.
+type BaseTokens struct {
+	WETH         common.Address
+	ETH          common.Address // the native token placeholder used by WooFi
+	USDC         common.Address
+	USDbC        common.Address
+	AaveWETH     common.Address
+	AaveUSDC     common.Address
+	MoonwellWETH common.Address
+}
+
+// ChainRegistry is the single source of chain and protocol data: RPCs, token and protocol
+// addresses, price feeds and the HTTP proxy for aggregator APIs.
+type ChainRegistry struct {
+	Proxy  string
+	Base   BaseTokens
+	chains map[string]*Chain
+
+	ApprovalSpenders map[common.Address][]common.Address // Base token -> protocol contracts
+	ChainlinkFeeds   map[common.Address]common.Address   // Base token -> USD aggregator
+	FallbackPrices   map[common.Address]*big.Float       // Base token -> USD
+}
+
+// legacyChainNames maps names that older configs used to the registry's names.
+var legacyChainNames = map[string]string{
+	"eth": "ethereum",
+}
+
+func init() {
+	var err error
+	if Chains, err = NewChainRegistry(nil); err != nil {
+		panic(fmt.Sprintf("invalid embedded chains.json: %v", err))
+	}
+}
+
+// NewChainRegistry builds the registry from the embedded defaults, the chains and proxy
+// sections of cfg, its legacy rpcs, tokens and bridge sections, and the environment:
+// BASE_PROXY replaces the proxy, BASE_RPC_<CHAIN> (comma-separated) replaces a chain's RPCs, and
+// ${VAR} in a proxy or RPC URL is taken from the environment, so keys stay out of the files.
+// cfg may be nil.
+func NewChainRegistry(cfg *Config) (*ChainRegistry, error) {
+	var raw ChainsConfig
+	if err := json.Unmarshal(defaultChainsJSON, &raw); err != nil {
+		return nil, err
+	}
+
+	if cfg != nil {
+		if cfg.Proxy != "" {
+			raw.Proxy = cfg.Proxy
+		}
+		for name, chain := range cfg.ChainsConfig {
+			raw.merge(name, chain)
+		}
+
+		// sections from before the chains section existed
+		for name, rpcs := range cfg.RPCs {
+			raw.merge(name, ChainConfig{RPCs: rpcs})
+		}
+		raw.merge("base", ChainConfig{Tokens: cfg.Tokens})
+		for name, addr := range cfg.BridgeConfig.SwapAddresses {
+			if addr != "" {
+				raw.merge(name, ChainConfig{Stargate: StargateConfig{SwapCA: addr}})
+			}
+		}
+		for name, addr := range cfg.BridgeConfig.FeeAdresses {
+			if addr != "" {
+				raw.merge(name, ChainConfig{Stargate: StargateConfig{FeeCA: addr}})
+			}
+		}
+	}
+
+	if proxy := os.Getenv("BASE_PROXY"); proxy != "" {
+		raw.Proxy = proxy
+	}
+	for name := range raw.Chains {
+		if rpcs := os.Getenv("BASE_RPC_" + strings.ToUpper(name)); rpcs != "" {
+			raw.merge(name, ChainConfig{RPCs: strings.Split(rpcs, ",")})
+		}
+	}
+
+	return raw.registry()
+}
+
+// merge applies the non-empty fields of override to the named chain. RPC lists are replaced,
+// maps are merged key by key.
+func (c *ChainsConfig) merge(name string, override ChainConfig) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := legacyChainNames[name]; ok {
+		name = alias
+	}
+	chain := c.Chains[name]
+
+	if override.ChainID != 0 {
+		chain.ChainID = override.ChainID
+	}
+	if len(override.RPCs) > 0 {
+		chain.RPCs = override.RPCs
+	}
+	if override.Stargate.SwapCA != "" {
+		chain.Stargate.SwapCA = override.Stargate.SwapCA
+	}
+	if override.Stargate.FeeCA != "" {
+		chain.Stargate.FeeCA = override.Stargate.FeeCA
+	}
+	chain.Tokens = mergeMap(chain.Tokens, override.Tokens)
+	chain.ApprovalSpenders = mergeMap(chain.ApprovalSpenders, override.ApprovalSpenders)
+	chain.ChainlinkFeeds = mergeMap(chain.ChainlinkFeeds, override.ChainlinkFeeds)
+	chain.FallbackPrices = mergeMap(chain.FallbackPrices, override.FallbackPrices)
+
+	if c.Chains == nil {
+		c.Chains = make(map[string]ChainConfig)
+	}
+	c.Chains[name] = chain
+}
+
+func (c *ChainsConfig) registry() (*ChainRegistry, error) {
+	proxy, err := expandEnv(c.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %v", err)
+	}
+
+	r := &ChainRegistry{
+		Proxy:            proxy,
+		chains:           make(map[string]*Chain, len(c.Chains)),
+		ApprovalSpenders: make(map[common.Address][]common.Address),
+		ChainlinkFeeds:   make(map[common.Address]common.Address),
+		FallbackPrices:   make(map[common.Address]*big.Float),
+	}
+
+	for name, raw := range c.Chains {
+		chain := &Chain{Name: name, ID: raw.ChainID, Tokens: make(map[string]common.Address, len(raw.Tokens))}
+
+		for _, rpc := range raw.RPCs {
+			if rpc = strings.TrimSpace(rpc); rpc == "" {
+				continue
+			}
+			url, err := expandEnv(rpc)
+			if err != nil {
+				return nil, fmt.Errorf("rpc of %s: %v", name, err)
+			}
+			chain.RPCs = append(chain.RPCs, url)
+		}
+		for token, addr := range raw.Tokens {
+			if chain.Tokens[strings.ToLower(token)], err = parseAddress(addr); err != nil {
+				return nil, fmt.Errorf("token %s on %s: %v", token, name, err)
+			}
+		}
+		if raw.Stargate.SwapCA != "" || raw.Stargate.FeeCA != "" {
+			if chain.StargateRouter, err = parseAddress(raw.Stargate.SwapCA); err != nil {
+				return nil, fmt.Errorf("stargate swap_ca on %s: %v", name, err)
+			}
+			if chain.StargateFeeQuoter, err = parseAddress(raw.Stargate.FeeCA); err != nil {
+				return nil, fmt.Errorf("stargate fee_ca on %s: %v", name, err)
+			}
+		}
+
+		r.chains[name] = chain
+	}
+
+	base, ok := r.chains["base"]
+	if !ok {
+		return nil, errors.New("chain base is missing")
+	}
+	for _, role := range []struct {
+		name string
+		addr *common.Address
+	}{
+		{"weth", &r.Base.WETH},
+		{"eth", &r.Base.ETH},
+		{"usdc", &r.Base.USDC},
+		{"usdbc", &r.Base.USDbC},
+		{"aave_weth", &r.Base.AaveWETH},
+		{"aave_usdc", &r.Base.AaveUSDC},
+		{"moonwell_weth", &r.Base.MoonwellWETH},
+	} {
+		if *role.addr, ok = base.Token(role.name); !ok {
+			return nil, fmt.Errorf("token %s is missing on base", role.name)
+		}
+	}
+
+	rawBase := c.Chains["base"]
+	for token, spenders := range rawBase.ApprovalSpenders {
+		addr, ok := base.Token(token)
+		if !ok {
+			return nil, fmt.Errorf("approval_spenders: unknown token %s", token)
+		}
+		for _, spender := range spenders {
+			spenderAddr, err := parseAddress(spender)
+			if err != nil {
+				return nil, fmt.Errorf("approval_spenders %s: %v", token, err)
+			}
+			r.ApprovalSpenders[addr] = append(r.ApprovalSpenders[addr], spenderAddr)
+		}
+	}
+	for token, feed := range rawBase.ChainlinkFeeds {
+		addr, ok := base.Token(token)
+		if !ok {
+			return nil, fmt.Errorf("chainlink_feeds: unknown token %s", token)
+		}
+		if r.ChainlinkFeeds[addr], err = parseAddress(feed); err != nil {
+			return nil, fmt.Errorf("chainlink_feeds %s: %v", token, err)
+		}
+	}
+	for token, price := range rawBase.FallbackPrices {
+		addr, ok := base.Token(token)
+		if !ok {
+			return nil, fmt.Errorf("fallback_prices_usd: unknown token %s", token)
+		}
+		r.FallbackPrices[addr] = big.NewFloat(price)
+	}
+
+	return r, nil
+}
+
+// Chain returns a chain by name. Names are lower case, e.g. "base" or "arbitrum".
+func (r *ChainRegistry) Chain(name string) (*Chain, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := legacyChainNames[name]; ok {
+		name = alias
+	}
+	chain, ok := r.chains[name]
+	return chain, ok
+}
+
+// Names returns the names of all known chains, sorted.
+func (r *ChainRegistry) Names() []string {
+	names := make([]string, 0, len(r.chains))
+	for name := range r.chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Token returns the address of a token by chain and token name.
+func (r *ChainRegistry) Token(chain, name string) (common.Address, bool) {
+	c, ok := r.Chain(chain)
+	if !ok {
+		return common.Address{}, false
+	}
+	return c.Token(name)
+}
+
+// RPCs returns the RPC endpoints of a chain, nil for an unknown chain.
+func (r *ChainRegistry) RPCs(chain string) []string {
+	if c, ok := r.Chain(chain); ok {
+		return c.RPCs
+	}
+	return nil
+}
+
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	merged := make(map[string]V, len(dst)+len(src))
+	for k, v := range dst {
+		merged[k] = v
+	}
+	for k, v := range src {
+		merged[k] = v
+	}
+	return merged
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+var envVariable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv substitutes ${VAR} from the environment and fails on variables that are not set,
+// so a missing RPC key is not silently turned into a broken URL. A bare $ is left alone, it may
+// be part of a proxy password.
+func expandEnv(s string) (string, error) {
+	var missing []string
+	expanded := envVariable.ReplaceAllStringFunc(s, func(match string) string {
+		name := envVariable.FindStringSubmatch(match)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variables are not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
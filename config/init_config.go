@@ -12,13 +12,29 @@ import (
 )
 
 type Config struct {
-	DexConfig         DexConfig         `json:"dex"`
-	BridgeConfig      BridgeConfig      `json:"bridge"`
-	RefuelConfig      RefuelConfig      `json:"refuel"`
-	DomainsConfig     DomainsConfig     `json:"domains"`
-	DmailConfig       DmailConfig       `json:"dmail"`
-	LiquidPoolsConfig LiquidPoolsConfig `json:"liquid_pools"`
-	NFTMintsConfig    NFTMintsConfig    `json:"nft_mints"`
+	Proxy             string                 `json:"proxy"`
+	ChainsConfig      map[string]ChainConfig `json:"chains"`
+	RPCs              map[string][]string    `json:"rpcs"`   // legacy, use chains.<name>.rpcs
+	Tokens            map[string]string      `json:"tokens"` // legacy, use chains.base.tokens
+	SwapTokens        []string               `json:"swap_tokens"`
+	DexConfig         DexConfig              `json:"dex"`
+	BridgeConfig      BridgeConfig           `json:"bridge"`
+	RefuelConfig      RefuelConfig           `json:"refuel"`
+	DomainsConfig     DomainsConfig          `json:"domains"`
+	DmailConfig       DmailConfig            `json:"dmail"`
+	LiquidPoolsConfig LiquidPoolsConfig      `json:"liquid_pools"`
+	NFTMintsConfig    NFTMintsConfig         `json:"nft_mints"`
+	GasConfig         map[string]GasConfig   `json:"gas"`
+	TxConfig          TxConfig               `json:"transactions"`
+	PriceConfig       PriceConfig            `json:"prices"`
+	StateConfig       StateConfig            `json:"state"`
+	RetryConfig       map[string]RetryConfig `json:"retry"`
+	ConcurrencyConfig ConcurrencyConfig      `json:"concurrency"`
+	ScheduleConfig    ScheduleConfig         `json:"schedule"`
+	ProxyConfig       ProxyConfig            `json:"proxies"`
+
+	// Chains is built from the defaults and the sections above by LoadConfig.
+	Chains *ChainRegistry `json:"-"`
 }
 
 type DexConfig struct {
@@ -103,6 +119,89 @@ type NFTConfig struct {
 	ABIPath string `json:"abi_path"`
 }
 
+type GasConfig struct {
+	Strategy          string  `json:"strategy"` // base_fee_multiplier | fee_history | fixed
+	BaseFeeMultiplier float64 `json:"base_fee_multiplier"`
+	Percentile        float64 `json:"percentile"`
+	Blocks            uint64  `json:"blocks"`
+	TipGwei           float64 `json:"tip_gwei"`
+	MaxFeeGwei        float64 `json:"max_fee_gwei"`
+}
+
+type TxConfig struct {
+	ReplaceAfterSec int   `json:"replace_after_sec"`
+	BumpPercent     int64 `json:"bump_percent"`
+	MaxReplacements *int  `json:"max_replacements"`
+	Cancel          *bool `json:"cancel_stuck"`
+	MaxWaitSec      int   `json:"max_wait_sec"`
+}
+
+// RetryConfig is keyed by action type, "default" applies to the rest.
+type RetryConfig struct {
+	MaxAttempts   int      `json:"max_attempts"`
+	BackoffSec    float64  `json:"backoff_sec"`
+	MaxBackoffSec float64  `json:"max_backoff_sec"`
+	RetryOn       []string `json:"retry_on"` // transient | revert | quote_expired | insufficient_funds
+}
+
+type ConcurrencyConfig struct {
+	MaxAccounts      int  `json:"max_accounts"` // 0 - all accounts at once
+	StartDelayMinSec int  `json:"start_delay_min_sec"`
+	StartDelayMaxSec int  `json:"start_delay_max_sec"`
+	Shuffle          bool `json:"shuffle"`
+}
+
+// ScheduleConfig spreads an account's actions over a calendar instead of one run.
+type ScheduleConfig struct {
+	Enabled           bool   `json:"enabled"`
+	ActionsPerDayMin  int    `json:"actions_per_day_min"`
+	ActionsPerDayMax  int    `json:"actions_per_day_max"`
+	ActiveDaysWeekMin int    `json:"active_days_per_week_min"`
+	ActiveDaysWeekMax int    `json:"active_days_per_week_max"`
+	HourFrom          int    `json:"hour_from"` // local hours, actions run in [hour_from, hour_to)
+	HourTo            int    `json:"hour_to"`
+	Timezone          string `json:"timezone"` // IANA name, empty - system local time
+}
+
+// ProxyConfig checks the wallets' own proxies, set with proxy/proxies in the account config.
+type ProxyConfig struct {
+	CheckURL         string `json:"check_url"`          // should answer with the exit IP, default - https://api.ipify.org
+	CheckIntervalSec int    `json:"check_interval_sec"` // 0 - only at start-up
+	CooldownSec      int    `json:"cooldown_sec"`       // a failed proxy is skipped this long, default - 300
+	RPC              bool   `json:"rpc"`                // send the wallet's RPC requests through its proxy too
+}
+
+type StateConfig struct {
+	RetryFailed  bool   `json:"retry_failed"`  // re-run failed steps when resuming
+	ResumePolicy string `json:"resume_policy"` // ask | resume | fresh | resume_failed_only, default - ask
+}
+
+type PriceConfig struct {
+	Sources     []string           `json:"sources"` // chainlink | quoter, tried in order
+	CacheTTLSec int                `json:"cache_ttl_sec"`
+	Chainlink   map[string]string  `json:"chainlink"`  // token name or address -> USD aggregator
+	StaticUSD   map[string]float64 `json:"static_usd"` // token name or address -> fallback price
+}
+
+func (c *Config) RPCsFor(chain string) []string {
+	return c.Chains.RPCs(chain)
+}
+
+func (c *Config) SwapTokenNames() []string {
+	if len(c.SwapTokens) > 0 {
+		return c.SwapTokens
+	}
+	return DefaultSwapTokens
+}
+
+func (c *Config) GasFor(chain string) (GasConfig, bool) {
+	if gasCfg, ok := c.GasConfig[chain]; ok {
+		return gasCfg, true
+	}
+	gasCfg, ok := c.GasConfig["default"]
+	return gasCfg, ok
+}
+
 func init() {
 	MaxUint256, _ = new(big.Int).SetString(MaxUint256Str, 10)
 
@@ -113,6 +212,19 @@ func init() {
 
 	Erc20ABI = &parsedABI
 
+	multicallABI, err := abi.JSON(bytes.NewReader(Multicall3JSON))
+	if err != nil {
+		logger.GlobalLogger.Fatalf("Ошибка при парсинге Multicall3 ABI: %v", err)
+	}
+
+	Multicall3ABI = &multicallABI
+
+	chainlinkABI, err := abi.JSON(bytes.NewReader(ChainlinkAggregatorJSON))
+	if err != nil {
+		logger.GlobalLogger.Fatalf("Ошибка при парсинге Chainlink ABI: %v", err)
+	}
+
+	ChainlinkABI = &chainlinkABI
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -126,5 +238,11 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %v", err)
 	}
 
+	chains, err := NewChainRegistry(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build chain registry: %v", err)
+	}
+	cfg.Chains = chains
+
 	return &cfg, nil
 }
@@ -6,11 +6,10 @@ import (
 	"base/logger"
 	"base/utils"
 	"context"
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -20,37 +19,83 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+const (
+	maxNonceAttempts = 3
+	gasWaitInterval  = 30 * time.Second
+)
+
+// ErrBroadcastUnconfirmed wraps every failure after a transaction was broadcast and before it was
+// mined with a known result: it may still land, so the action must not be sent again.
+var ErrBroadcastUnconfirmed = errors.New("transaction broadcast, outcome unconfirmed")
+
 type Client struct {
-	Client   *ethclient.Client
-	FilePath string
-	Txs      sync.Map
+	Chain       string
+	Client      *ethclient.Client
+	Pool        *RPCPool
+	Journal     *Journal
+	Txs         sync.Map
+	Nonces      *NonceManager
+	Tokens      *TokenRegistry
+	Prices      PriceSource
+	Gas         GasStrategy
+	Replacement ReplacementPolicy
+	DryRun      bool
+
+	chainMu       sync.Mutex
+	cachedChainID *big.Int
 }
 
-func NewClient(rpc string, filepath string) (*Client, error) {
-	client, err := ethclient.Dial(rpc)
+func NewClient(chain string, rpcs []string) (*Client, error) {
+	pool, err := NewRPCPool(chain, rpcs)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcClient, err := rpc.DialOptions(context.Background(), pool.endpoints[0].URL.String(), rpc.WithHTTPClient(&http.Client{Transport: pool}))
 	if err != nil {
 		return nil, err
 	}
+	pool.StartHealthCheck()
 
 	return &Client{
-		Client:   client,
-		FilePath: filepath,
+		Chain:       chain,
+		Client:      ethclient.NewClient(rpcClient),
+		Pool:        pool,
+		Nonces:      DefaultNonceManager,
+		Tokens:      DefaultTokenRegistry,
+		Prices:      DefaultPriceSource(),
+		Gas:         DefaultGasStrategy(),
+		Replacement: DefaultReplacementPolicy(),
 	}, nil
 }
 
 func CloseAllClients(clients map[string]*Client) {
+	journals := make(map[*Journal]struct{})
+	defer func() {
+		for journal := range journals {
+			journal.Close()
+		}
+	}()
+
 	for _, client := range clients {
+		if client.Journal != nil {
+			journals[client.Journal] = struct{}{}
+		}
 		if client.Client != nil {
 			client.Client.Close()
 		}
+		if client.Pool != nil {
+			client.Pool.Close()
+		}
 	}
 }
 
-func (c *Client) BalanceCheck(owner, tokenAddr common.Address) (*big.Int, error) {
+func (c *Client) BalanceCheck(ctx context.Context, owner, tokenAddr common.Address) (*big.Int, error) {
 	if utils.IsNativeToken(tokenAddr) {
-		balance, err := c.Client.BalanceAt(context.Background(), owner, nil)
+		balance, err := c.Client.BalanceAt(ctx, owner, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get native coin balance: %v", err)
 		}
@@ -62,7 +107,7 @@ func (c *Client) BalanceCheck(owner, tokenAddr common.Address) (*big.Int, error)
 		return nil, fmt.Errorf("failed to pack data: %v", err)
 	}
 
-	result, err := c.CallCA(tokenAddr, data)
+	result, err := c.CallCA(ctx, tokenAddr, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call contract: %v", err)
 	}
@@ -75,68 +120,80 @@ func (c *Client) BalanceCheck(owner, tokenAddr common.Address) (*big.Int, error)
 	return balance, nil
 }
 
-func (c *Client) NormalizeBalance(balance *big.Int, token common.Address) (*big.Float, error) {
-	decimals, ok := config.TokenDecimals[token]
-	if !ok {
-		return nil, fmt.Errorf("decimals not found for token %s", token.Hex())
+func (c *Client) NormalizeBalance(ctx context.Context, balance *big.Int, token common.Address) (*big.Float, error) {
+	decimals, err := c.Decimals(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("decimals not found for token %s: %v", token.Hex(), err)
 	}
 
-	price, ok := config.TokenPrice[token]
-	if !ok {
-		return nil, fmt.Errorf("price not found for token %s", token.Hex())
+	price, err := c.Prices.Price(ctx, c, token)
+	if err != nil {
+		return nil, err
 	}
 
-	normalized := new(big.Float).SetInt(balance)
-	divisor := new(big.Float).SetFloat64(math.Pow10(int(decimals)))
-	normalized.Quo(normalized, divisor)
-
+	normalized := scaleDown(balance, decimals)
 	normalized.Mul(normalized, price)
 
 	return normalized, nil
 }
 
-func (c *Client) CallCA(toCA common.Address, data []byte) ([]byte, error) {
+func (c *Client) CallCA(ctx context.Context, toCA common.Address, data []byte) ([]byte, error) {
 	callMsg := ethereum.CallMsg{
 		To:   &toCA,
 		Data: data,
 	}
 
-	return c.Client.CallContract(context.Background(), callMsg, nil)
+	return c.Client.CallContract(ctx, callMsg, nil)
 }
 
-func (c *Client) GetGasValues(msg ethereum.CallMsg) (uint64, *big.Int, *big.Int, error) {
-	header, err := c.Client.HeaderByNumber(context.Background(), nil)
+func (c *Client) GetGasValues(ctx context.Context, msg ethereum.CallMsg, maxGasPrice *big.Int) (uint64, GasFees, error) {
+	fees, err := c.waitForGasPrice(ctx, maxGasPrice)
 	if err != nil {
-		return 0, nil, nil, err
+		return 0, GasFees{}, err
 	}
-	baseFee := header.BaseFee
-
-	maxPriorityFeePerGas := big.NewInt(1e7)
-	maxFeePerGas := new(big.Int).Add(baseFee, maxPriorityFeePerGas)
 
-	gasLimit, err := c.Client.EstimateGas(context.Background(), msg)
+	gasLimit, err := c.Client.EstimateGas(ctx, msg)
 	if err != nil {
-		return 0, nil, nil, err
+		return 0, GasFees{}, err
 	}
 
-	return gasLimit, maxPriorityFeePerGas, maxFeePerGas, nil
+	return gasLimit, fees, nil
 }
 
-func (c *Client) GetNonce(address common.Address) uint64 {
-	nonce, err := c.Client.PendingNonceAt(context.Background(), address)
-	if err != nil {
-		logger.GlobalLogger.Warnf("Failed to get nonce for address %s: %v", address.Hex(), err)
-		return 0
+func (c *Client) waitForGasPrice(ctx context.Context, maxGasPrice *big.Int) (GasFees, error) {
+	for {
+		fees, err := c.Gas.Fees(ctx, c)
+		if err != nil {
+			return GasFees{}, err
+		}
+
+		if maxGasPrice == nil || maxGasPrice.Sign() <= 0 {
+			return fees, nil
+		}
+
+		if fees.EffectivePrice().Cmp(maxGasPrice) <= 0 {
+			if fees.FeeCap.Cmp(maxGasPrice) > 0 {
+				fees.FeeCap = new(big.Int).Set(maxGasPrice)
+			}
+			if fees.Tip.Cmp(fees.FeeCap) > 0 {
+				fees.Tip = new(big.Int).Set(fees.FeeCap)
+			}
+			return fees, nil
+		}
+
+		logger.GlobalLogger.Infof("Gas price %s wei is above the limit of %s wei, waiting %v...", fees.EffectivePrice().String(), maxGasPrice.String(), gasWaitInterval)
+		if err := utils.Sleep(ctx, gasWaitInterval); err != nil {
+			return GasFees{}, err
+		}
 	}
-	return nonce
 }
 
-func (c *Client) ApproveTx(tokenAddr, spender common.Address, acc *account.Account, amount *big.Int, rollback bool) (*types.Transaction, error) {
+func (c *Client) ApproveTx(ctx context.Context, tokenAddr, spender common.Address, acc *account.Account, amount *big.Int, rollback bool) (*types.Transaction, error) {
 	if utils.IsNativeToken(tokenAddr) {
 		return nil, nil
 	}
 
-	allowance, err := c.Allowance(tokenAddr, acc.Address, spender)
+	allowance, err := c.Allowance(ctx, tokenAddr, acc.Address, spender)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get allowance: %v", err)
 	}
@@ -158,15 +215,17 @@ func (c *Client) ApproveTx(tokenAddr, spender common.Address, acc *account.Accou
 	}
 
 	logger.GlobalLogger.Infof("Approve transaction...")
-	if err := c.SendTransaction(acc.PrivateKey, acc.Address, spender, c.GetNonce(acc.Address), big.NewInt(0), approveData); err != nil {
+	if err := c.SendTransaction(ctx, acc, spender, big.NewInt(0), approveData); err != nil {
 		return nil, err
 	}
 
-	time.Sleep(time.Second * 15)
-	return nil, nil
+	if c.DryRun {
+		return nil, nil
+	}
+	return nil, utils.Sleep(ctx, time.Second*15)
 }
 
-func (c *Client) Allowance(tokenAddr, owner, spender common.Address) (*big.Int, error) {
+func (c *Client) Allowance(ctx context.Context, tokenAddr, owner, spender common.Address) (*big.Int, error) {
 	data, err := config.Erc20ABI.Pack("allowance", owner, spender)
 	if err != nil {
 		return nil, fmt.Errorf("failed to pack allowance data: %v", err)
@@ -177,7 +236,7 @@ func (c *Client) Allowance(tokenAddr, owner, spender common.Address) (*big.Int,
 		Data: data,
 	}
 
-	result, err := c.Client.CallContract(context.Background(), msg, nil)
+	result, err := c.Client.CallContract(ctx, msg, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call contract: %v", err)
 	}
@@ -190,94 +249,118 @@ func (c *Client) Allowance(tokenAddr, owner, spender common.Address) (*big.Int,
 	return allowance, nil
 }
 
-func (c *Client) SendNativeToken(privateKey *ecdsa.PrivateKey, from, to common.Address, amount *big.Int) error {
-	return c.SendTransaction(privateKey, from, to, c.GetNonce(from), amount, nil)
+func (c *Client) SendNativeToken(ctx context.Context, acc *account.Account, to common.Address, amount *big.Int) error {
+	return c.SendTransaction(ctx, acc, to, amount, nil)
 }
 
-func (c *Client) SendERC20Token(privateKey *ecdsa.PrivateKey, tokenAddress, from, to common.Address, amount *big.Int) error {
+func (c *Client) SendERC20Token(ctx context.Context, acc *account.Account, tokenAddress, to common.Address, amount *big.Int) error {
 	transferData, err := config.Erc20ABI.Pack("transfer", to, amount)
 	if err != nil {
 		return fmt.Errorf("failed to pack transfer data: %v", err)
 	}
 
-	return c.SendTransaction(privateKey, from, tokenAddress, c.GetNonce(from), big.NewInt(0), transferData)
+	return c.SendTransaction(ctx, acc, tokenAddress, big.NewInt(0), transferData)
 }
 
-func (c *Client) SendTransaction(privateKey *ecdsa.PrivateKey, ownerAddr, CA common.Address, nonce uint64, value *big.Int, txData []byte) error {
-	chainID, err := c.Client.NetworkID(context.Background())
+// SendTransaction signs, broadcasts and waits for the transaction. ctx is honoured until the
+// broadcast: once the transaction is sent, it is followed to a receipt even if ctx is cancelled,
+// so a shutdown never leaves a transaction unaccounted for.
+func (c *Client) SendTransaction(ctx context.Context, acc *account.Account, CA common.Address, value *big.Int, txData []byte) error {
+	if c.DryRun {
+		return c.simulateTransaction(ctx, acc, CA, value, txData)
+	}
+
+	ownerAddr := acc.Address
+
+	chainID, err := c.chainID()
 	if err != nil {
 		return fmt.Errorf("failed to get ChainID: %v", err)
 	}
 
-	gasLimit, maxPriorityFeePerGas, maxFeePerGas, err := c.GetGasValues(ethereum.CallMsg{
+	gasLimit, fees, err := c.GetGasValues(ctx, ethereum.CallMsg{
 		From:  ownerAddr,
 		To:    &CA,
 		Value: value,
 		Data:  txData,
-	})
+	}, acc.MaxGasPrice)
 	if err != nil {
 		return fmt.Errorf("failed to estimate gas: %v", err)
 	}
 
-	dynamicTx := types.DynamicFeeTx{
-		ChainID:   chainID,
-		Nonce:     nonce,
-		GasTipCap: maxPriorityFeePerGas,
-		GasFeeCap: maxFeePerGas,
-		Gas:       gasLimit,
-		To:        &CA,
-		Value:     value,
-		Data:      txData,
-	}
+	var signedTx *types.Transaction
+	for attempt := 1; attempt <= maxNonceAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	signedTx, err := types.SignTx(types.NewTx(&dynamicTx), types.LatestSignerForChainID(chainID), privateKey)
-	if err != nil {
-		return fmt.Errorf("failed to sign transaction: %v", err)
-	}
+		nonce, err := c.GetNonce(ctx, ownerAddr)
+		if err != nil {
+			return err
+		}
+
+		dynamicTx := types.DynamicFeeTx{
+			ChainID:   new(big.Int).SetUint64(chainID),
+			Nonce:     nonce,
+			GasTipCap: fees.Tip,
+			GasFeeCap: fees.FeeCap,
+			Gas:       gasLimit,
+			To:        &CA,
+			Value:     value,
+			Data:      txData,
+		}
+
+		signedTx, err = types.SignTx(types.NewTx(&dynamicTx), types.LatestSignerForChainID(dynamicTx.ChainID), acc.PrivateKey)
+		if err != nil {
+			c.releaseNonce(ownerAddr, nonce)
+			return fmt.Errorf("failed to sign transaction: %v", err)
+		}
+
+		err = c.Client.SendTransaction(context.WithoutCancel(ctx), signedTx)
+		if err == nil {
+			break
+		}
+		if isAlreadyKnown(err) {
+			logger.GlobalLogger.Infof("Transaction %s is already known to the node", signedTx.Hash().Hex())
+			break
+		}
 
-	if err = c.Client.SendTransaction(context.Background(), signedTx); err != nil {
+		if isNonceError(err) && attempt < maxNonceAttempts {
+			logger.GlobalLogger.Warnf("Nonce %d rejected for %s (%v), resyncing with node...", nonce, ownerAddr.Hex(), err)
+			c.resyncNonce(ownerAddr)
+			continue
+		}
+
+		c.abandonNonce(ownerAddr, nonce, err)
 		return fmt.Errorf("failed to send transaction: %v", err)
 	}
 
 	logger.GlobalLogger.Infof("Transaction sent: https://basescan.org/tx/%s", signedTx.Hash().Hex())
 
-	// c.SaveData(ownerAddr, signedTx.Hash())
-	return c.waitForTransactionSuccess(signedTx.Hash(), 1*time.Minute)
-}
-
-func (c *Client) waitForTransactionSuccess(txHash common.Hash, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
+	tracked := c.track(ctx, acc, signedTx)
+	receipt, err := c.waitForTransaction(tracked)
+	acc.RecordTxs(tracked.hashes...)
+	if err != nil {
+		c.journal(acc, tracked, nil, TxStatusTimeout)
+		return fmt.Errorf("%w: %v", ErrBroadcastUnconfirmed, err)
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return errors.New("transaction wait timeout")
-		case <-ticker.C:
-			receipt, err := c.Client.TransactionReceipt(context.Background(), txHash)
-			if err != nil {
-				if err.Error() == "not found" {
-					logger.GlobalLogger.Infof("Transaction %s not yet found in the blockchain, retrying...", txHash.Hex())
-					continue
-				}
-				return fmt.Errorf("error getting transaction receipt: %v", err)
-			}
+	if receipt.TxHash == tracked.cancelHash {
+		c.journal(acc, tracked, receipt, TxStatusCancelled)
+		return fmt.Errorf("%w: transaction %s was cancelled by %s", ErrBroadcastUnconfirmed, tracked.original.Hex(), receipt.TxHash.Hex())
+	}
 
-			if receipt.Status == 1 {
-				logger.GlobalLogger.Infof("Transaction %s succeeded", txHash.Hex())
-				return nil
-			} else {
-				c.logTransactionError(txHash, receipt)
-				return errors.New("transaction failed")
-			}
-		}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		c.journal(acc, tracked, receipt, TxStatusFailed)
+		c.logTransactionError(tracked.ctx, receipt.TxHash, receipt)
+		return errors.New("transaction failed")
 	}
+
+	c.journal(acc, tracked, receipt, TxStatusSuccess)
+	logger.GlobalLogger.Infof("Transaction %s succeeded", receipt.TxHash.Hex())
+	return nil
 }
 
-func (c *Client) logTransactionError(txHash common.Hash, receipt *types.Receipt) {
+func (c *Client) logTransactionError(ctx context.Context, txHash common.Hash, receipt *types.Receipt) {
 	logger.GlobalLogger.Errorf("Transaction failed. txHash: %s", txHash.Hex())
 
 	for _, logEntry := range receipt.Logs {
@@ -288,13 +371,13 @@ func (c *Client) logTransactionError(txHash common.Hash, receipt *types.Receipt)
 		)
 	}
 
-	tx, isPending, err := c.Client.TransactionByHash(context.Background(), txHash)
+	tx, isPending, err := c.Client.TransactionByHash(ctx, txHash)
 	if err != nil {
 		logger.GlobalLogger.Warnf("Error getting transaction details: %v", err)
 		return
 	}
 
-	chainID, err := c.Client.NetworkID(context.Background())
+	chainID, err := c.Client.NetworkID(ctx)
 	if err != nil {
 		logger.GlobalLogger.Warnf("Error getting ChainID: %v", err)
 		return
@@ -315,7 +398,6 @@ func (c *Client) logTransactionError(txHash common.Hash, receipt *types.Receipt)
 		Data:     tx.Data(),
 	}
 
-	ctx := context.Background()
 	blockNumber := receipt.BlockNumber
 	result, callErr := c.Client.CallContract(ctx, callMsg, blockNumber)
 
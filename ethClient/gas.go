@@ -0,0 +1,195 @@
+package ethClient
+
+import (
+	"base/config"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultFeeHistoryBlocks  = 10
+	defaultPercentile        = 50
+	defaultBaseFeeMultiplier = 2.0
+)
+
+var defaultPriorityFee = big.NewInt(1e7) // 0.01 gwei
+
+type GasFees struct {
+	BaseFee *big.Int
+	Tip     *big.Int
+	FeeCap  *big.Int
+}
+
+// EffectivePrice is the price per gas the transaction would pay if it landed in the next block.
+func (f GasFees) EffectivePrice() *big.Int {
+	price := new(big.Int).Add(f.BaseFee, f.Tip)
+	if price.Cmp(f.FeeCap) > 0 {
+		return new(big.Int).Set(f.FeeCap)
+	}
+	return price
+}
+
+type GasStrategy interface {
+	Fees(ctx context.Context, c *Client) (GasFees, error)
+}
+
+type FeeHistoryStrategy struct {
+	Blocks            uint64
+	Percentile        float64
+	BaseFeeMultiplier float64
+	MaxFeeCap         *big.Int
+}
+
+func (s FeeHistoryStrategy) Fees(ctx context.Context, c *Client) (GasFees, error) {
+	history, err := c.Client.FeeHistory(ctx, s.Blocks, nil, []float64{s.Percentile})
+	if err != nil {
+		return GasFees{}, fmt.Errorf("failed to get fee history: %v", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return GasFees{}, errors.New("empty fee history")
+	}
+
+	rewards := make([]*big.Int, 0, len(history.Reward))
+	for _, blockRewards := range history.Reward {
+		if len(blockRewards) > 0 && blockRewards[0] != nil {
+			rewards = append(rewards, blockRewards[0])
+		}
+	}
+
+	tip := new(big.Int).Set(defaultPriorityFee)
+	if len(rewards) > 0 {
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		tip.Set(rewards[len(rewards)/2])
+	}
+
+	// the last entry is the base fee of the pending block
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	return buildFees(baseFee, tip, s.BaseFeeMultiplier, s.MaxFeeCap), nil
+}
+
+type BaseFeeMultiplierStrategy struct {
+	Multiplier float64
+	Tip        *big.Int
+	MaxFeeCap  *big.Int
+}
+
+func (s BaseFeeMultiplierStrategy) Fees(ctx context.Context, c *Client) (GasFees, error) {
+	header, err := c.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return GasFees{}, err
+	}
+	if header.BaseFee == nil {
+		return GasFees{}, errors.New("chain does not support EIP-1559 base fee")
+	}
+
+	return buildFees(header.BaseFee, s.Tip, s.Multiplier, s.MaxFeeCap), nil
+}
+
+type FixedStrategy struct {
+	Tip    *big.Int
+	FeeCap *big.Int
+}
+
+func (s FixedStrategy) Fees(ctx context.Context, c *Client) (GasFees, error) {
+	header, err := c.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return GasFees{}, err
+	}
+
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = big.NewInt(0)
+	}
+
+	return GasFees{
+		BaseFee: baseFee,
+		Tip:     new(big.Int).Set(s.Tip),
+		FeeCap:  new(big.Int).Set(s.FeeCap),
+	}, nil
+}
+
+func buildFees(baseFee, tip *big.Int, multiplier float64, maxFeeCap *big.Int) GasFees {
+	scaled, _ := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(multiplier)).Int(nil)
+	feeCap := new(big.Int).Add(scaled, tip)
+
+	if maxFeeCap != nil && maxFeeCap.Sign() > 0 && feeCap.Cmp(maxFeeCap) > 0 {
+		feeCap.Set(maxFeeCap)
+	}
+
+	if tip.Cmp(feeCap) > 0 {
+		tip = feeCap
+	}
+
+	return GasFees{
+		BaseFee: new(big.Int).Set(baseFee),
+		Tip:     new(big.Int).Set(tip),
+		FeeCap:  feeCap,
+	}
+}
+
+func DefaultGasStrategy() GasStrategy {
+	return BaseFeeMultiplierStrategy{
+		Multiplier: defaultBaseFeeMultiplier,
+		Tip:        defaultPriorityFee,
+	}
+}
+
+func NewGasStrategy(cfg config.GasConfig) (GasStrategy, error) {
+	tip := defaultPriorityFee
+	if cfg.TipGwei > 0 {
+		tip = GweiToWei(cfg.TipGwei)
+	}
+
+	multiplier := cfg.BaseFeeMultiplier
+	if multiplier <= 0 {
+		multiplier = defaultBaseFeeMultiplier
+	}
+
+	var maxFeeCap *big.Int
+	if cfg.MaxFeeGwei > 0 {
+		maxFeeCap = GweiToWei(cfg.MaxFeeGwei)
+	}
+
+	switch strings.ToLower(cfg.Strategy) {
+	case "", "base_fee_multiplier":
+		return BaseFeeMultiplierStrategy{
+			Multiplier: multiplier,
+			Tip:        tip,
+			MaxFeeCap:  maxFeeCap,
+		}, nil
+	case "fee_history":
+		blocks := cfg.Blocks
+		if blocks == 0 {
+			blocks = defaultFeeHistoryBlocks
+		}
+		percentile := cfg.Percentile
+		if percentile <= 0 || percentile > 100 {
+			percentile = defaultPercentile
+		}
+		return FeeHistoryStrategy{
+			Blocks:            blocks,
+			Percentile:        percentile,
+			BaseFeeMultiplier: multiplier,
+			MaxFeeCap:         maxFeeCap,
+		}, nil
+	case "fixed":
+		if maxFeeCap == nil {
+			return nil, errors.New("fixed gas strategy requires max_fee_gwei")
+		}
+		return FixedStrategy{
+			Tip:    tip,
+			FeeCap: maxFeeCap,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown gas strategy: %s", cfg.Strategy)
+	}
+}
+
+func GweiToWei(gwei float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(1e9)).Int(nil)
+	return wei
+}
@@ -0,0 +1,186 @@
+package ethClient
+
+import (
+	"base/account"
+	"base/config"
+	"base/logger"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+const (
+	TxStatusSuccess   = "success"
+	TxStatusFailed    = "failed"
+	TxStatusCancelled = "cancelled"
+	TxStatusTimeout   = "timeout"
+)
+
+// JournalRecord is one line of the transaction journal. Amounts are decimal wei strings so the
+// file stays readable by tools without big number support.
+type JournalRecord struct {
+	Time         time.Time `json:"time"`
+	AccountID    int       `json:"account_id"`
+	Address      string    `json:"address"`
+	Chain        string    `json:"chain"`
+	Action       string    `json:"action,omitempty"`
+	Module       string    `json:"module,omitempty"`
+	To           string    `json:"to"`
+	Value        string    `json:"value"`
+	Nonce        uint64    `json:"nonce"`
+	GasUsed      uint64    `json:"gas_used"`
+	Fee          string    `json:"fee"`
+	VolumeUSD    float64   `json:"volume_usd"`
+	Status       string    `json:"status"`
+	Hash         string    `json:"hash"`
+	Replacements int       `json:"replacements,omitempty"`
+}
+
+// Journal appends records to a JSONL file shared by all clients.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func NewJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %v", path, err)
+	}
+	return &Journal{path: path, file: file}, nil
+}
+
+func (j *Journal) Append(record JournalRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// ReadJournal loads every record that matches the filter, a nil filter keeps all of them.
+// A truncated last line left by a crash is skipped.
+func ReadJournal(path string, filter func(JournalRecord) bool) ([]JournalRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []JournalRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record JournalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if filter == nil || filter(record) {
+			records = append(records, record)
+		}
+	}
+
+	return records, scanner.Err()
+}
+
+func (c *Client) journal(acc *account.Account, tracked *trackedTx, receipt *types.Receipt, status string) {
+	if c.Journal == nil {
+		return
+	}
+
+	record := JournalRecord{
+		Time:         time.Now().UTC(),
+		AccountID:    acc.AccountID,
+		Address:      acc.Address.Hex(),
+		Chain:        c.Chain,
+		Action:       acc.CurrentAction,
+		Module:       acc.CurrentModule,
+		Value:        tracked.initial.Value().String(),
+		Nonce:        tracked.nonce,
+		Fee:          "0",
+		Status:       status,
+		Hash:         tracked.current.Hash().Hex(),
+		Replacements: tracked.replacements,
+	}
+	if to := tracked.initial.To(); to != nil {
+		record.To = to.Hex()
+	}
+
+	if receipt != nil {
+		record.Hash = receipt.TxHash.Hex()
+		record.GasUsed = receipt.GasUsed
+		if receipt.EffectiveGasPrice != nil {
+			record.Fee = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice).String()
+		}
+		if receipt.TxHash == tracked.cancelHash {
+			record.To = acc.Address.Hex()
+			record.Value = "0"
+		}
+		if status == TxStatusSuccess {
+			record.VolumeUSD = c.volumeUSD(tracked.ctx, acc, tracked.initial.Value(), receipt)
+		}
+	}
+
+	if err := c.Journal.Append(record); err != nil {
+		logger.GlobalLogger.Warnf("Failed to write transaction %s to journal: %v", record.Hash, err)
+	}
+}
+
+// volumeUSD values everything that left the wallet in the transaction: the native value plus
+// every ERC20 Transfer emitted from the account. Tokens without a price are ignored.
+func (c *Client) volumeUSD(ctx context.Context, acc *account.Account, value *big.Int, receipt *types.Receipt) float64 {
+	total := new(big.Float)
+
+	if value != nil && value.Sign() > 0 {
+		if usd, err := c.NormalizeBalance(ctx, value, config.Chains.Base.WETH); err == nil {
+			total.Add(total, usd)
+		}
+	}
+
+	for _, log := range receipt.Logs {
+		if len(log.Topics) != 3 || log.Topics[0] != transferTopic || len(log.Data) != 32 {
+			continue
+		}
+		if common.BytesToAddress(log.Topics[1].Bytes()) != acc.Address {
+			continue
+		}
+
+		usd, err := c.NormalizeBalance(ctx, new(big.Int).SetBytes(log.Data), log.Address)
+		if err != nil {
+			continue
+		}
+		total.Add(total, usd)
+	}
+
+	volume, _ := total.Float64()
+	return volume
+}
@@ -0,0 +1,175 @@
+package ethClient
+
+import (
+	"base/config"
+	"base/utils"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// keeps a single eth_call well below the gas cap of public RPCs
+const multicallChunkSize = 300
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// TokenQuery describes one (owner, token) pair. Spender is optional, the allowance is only
+// read when it is set.
+type TokenQuery struct {
+	Owner   common.Address
+	Token   common.Address
+	Spender common.Address
+}
+
+// TokenData holds the batched reads for a TokenQuery. Fields that could not be read are nil.
+type TokenData struct {
+	Balance   *big.Int
+	Allowance *big.Int
+	Decimals  *uint8
+}
+
+// multicall runs the calls through Multicall3.aggregate3 in chunks. Failed sub-calls do not
+// revert the batch, their results come back with Success set to false.
+func (c *Client) multicall(ctx context.Context, calls []multicallCall) ([]multicallResult, error) {
+	results := make([]multicallResult, 0, len(calls))
+
+	for start := 0; start < len(calls); start += multicallChunkSize {
+		end := min(start+multicallChunkSize, len(calls))
+
+		data, err := config.Multicall3ABI.Pack("aggregate3", calls[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack multicall: %v", err)
+		}
+
+		raw, err := c.Client.CallContract(ctx, ethereum.CallMsg{To: &config.Multicall3, Data: data}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("multicall failed: %v", err)
+		}
+
+		out, err := config.Multicall3ABI.Unpack("aggregate3", raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack multicall result: %v", err)
+		}
+
+		chunk := *abi.ConvertType(out[0], new([]multicallResult)).(*[]multicallResult)
+		if len(chunk) != end-start {
+			return nil, fmt.Errorf("multicall returned %d results for %d calls", len(chunk), end-start)
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
+
+// BatchTokenData reads balances, allowances and decimals for all queries in one round trip per
+// chunk. Native ETH balances are read through Multicall3.getEthBalance.
+func (c *Client) BatchTokenData(ctx context.Context, queries []TokenQuery) ([]TokenData, error) {
+	type slot struct {
+		query int
+		field string
+	}
+
+	var (
+		calls []multicallCall
+		slots []slot
+	)
+	add := func(query int, field string, target common.Address, contract *abi.ABI, method string, args ...interface{}) error {
+		data, err := contract.Pack(method, args...)
+		if err != nil {
+			return fmt.Errorf("failed to pack %s: %v", method, err)
+		}
+		calls = append(calls, multicallCall{Target: target, AllowFailure: true, CallData: data})
+		slots = append(slots, slot{query: query, field: field})
+		return nil
+	}
+
+	data := make([]TokenData, len(queries))
+	for i, q := range queries {
+		if utils.IsNativeToken(q.Token) {
+			decimals := uint8(18)
+			data[i].Decimals = &decimals
+			data[i].Allowance = new(big.Int).Set(config.MaxUint256)
+			if err := add(i, "balance", config.Multicall3, config.Multicall3ABI, "getEthBalance", q.Owner); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := add(i, "balance", q.Token, config.Erc20ABI, "balanceOf", q.Owner); err != nil {
+			return nil, err
+		}
+		if err := add(i, "decimals", q.Token, config.Erc20ABI, "decimals"); err != nil {
+			return nil, err
+		}
+		if q.Spender != (common.Address{}) {
+			if err := add(i, "allowance", q.Token, config.Erc20ABI, "allowance", q.Owner, q.Spender); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	results, err := c.multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, res := range results {
+		if !res.Success || len(res.ReturnData) == 0 {
+			continue
+		}
+
+		s := slots[i]
+		switch s.field {
+		case "decimals":
+			out, err := config.Erc20ABI.Unpack("decimals", res.ReturnData)
+			if err != nil || len(out) == 0 {
+				continue
+			}
+			if decimals, ok := out[0].(uint8); ok {
+				data[s.query].Decimals = &decimals
+			}
+		case "allowance":
+			data[s.query].Allowance = new(big.Int).SetBytes(res.ReturnData[:min(len(res.ReturnData), 32)])
+		case "balance":
+			data[s.query].Balance = new(big.Int).SetBytes(res.ReturnData[:min(len(res.ReturnData), 32)])
+		}
+	}
+
+	return data, nil
+}
+
+// BatchBalances returns the owner's balances for the given tokens. Tokens whose balance could
+// not be read are missing from the map.
+func (c *Client) BatchBalances(ctx context.Context, owner common.Address, tokens []common.Address) (map[common.Address]*big.Int, error) {
+	queries := make([]TokenQuery, len(tokens))
+	for i, token := range tokens {
+		queries[i] = TokenQuery{Owner: owner, Token: token}
+	}
+
+	data, err := c.BatchTokenData(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[common.Address]*big.Int, len(tokens))
+	for i, token := range tokens {
+		if data[i].Balance != nil {
+			balances[token] = data[i].Balance
+		}
+	}
+
+	return balances, nil
+}
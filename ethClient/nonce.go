@@ -0,0 +1,179 @@
+package ethClient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type nonceKey struct {
+	chainID uint64
+	address common.Address
+}
+
+type nonceState struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64
+}
+
+type NonceManager struct {
+	mu     sync.Mutex
+	states map[nonceKey]*nonceState
+}
+
+var DefaultNonceManager = NewNonceManager()
+
+func NewNonceManager() *NonceManager {
+	return &NonceManager{
+		states: make(map[nonceKey]*nonceState),
+	}
+}
+
+func (nm *NonceManager) state(chainID uint64, address common.Address) *nonceState {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	key := nonceKey{chainID: chainID, address: address}
+	st, ok := nm.states[key]
+	if !ok {
+		st = &nonceState{}
+		nm.states[key] = st
+	}
+	return st
+}
+
+// Acquire hands out the next nonce for the address on the given chain. Released
+// nonces are reused first so that a failed send does not leave a gap.
+func (nm *NonceManager) Acquire(chainID uint64, address common.Address, fetch func() (uint64, error)) (uint64, error) {
+	st := nm.state(chainID, address)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.synced {
+		pending, err := fetch()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce for %s: %v", address.Hex(), err)
+		}
+		st.next = pending
+		st.released = nil
+		st.synced = true
+	}
+
+	if len(st.released) > 0 {
+		nonce := st.released[0]
+		st.released = st.released[1:]
+		return nonce, nil
+	}
+
+	nonce := st.next
+	st.next++
+	return nonce, nil
+}
+
+// Release returns a nonce that was acquired but never reached the node.
+func (nm *NonceManager) Release(chainID uint64, address common.Address, nonce uint64) {
+	st := nm.state(chainID, address)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.synced || nonce >= st.next {
+		return
+	}
+
+	if nonce == st.next-1 {
+		st.next--
+		for len(st.released) > 0 && st.released[len(st.released)-1] == st.next-1 {
+			st.released = st.released[:len(st.released)-1]
+			st.next--
+		}
+		return
+	}
+
+	st.released = append(st.released, nonce)
+	sort.Slice(st.released, func(i, j int) bool { return st.released[i] < st.released[j] })
+}
+
+// Reset drops the local view so the next Acquire resyncs from the node.
+func (nm *NonceManager) Reset(chainID uint64, address common.Address) {
+	st := nm.state(chainID, address)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.synced = false
+	st.released = nil
+}
+
+// Abandon gives up a nonce whose transaction failed to send. A nonce the node rejected as
+// already used must not be handed out again, so the local view is resynced; any other nonce
+// never reached the chain and is released for reuse.
+func (nm *NonceManager) Abandon(chainID uint64, address common.Address, nonce uint64, sendErr error) {
+	if isNonceError(sendErr) {
+		nm.Reset(chainID, address)
+		return
+	}
+	nm.Release(chainID, address, nonce)
+}
+
+func isNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// isAlreadyKnown reports that the node already has this exact signed transaction in its pool,
+// e.g. after a send that timed out on the client side but reached the node.
+func isAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "already known")
+}
+
+func (c *Client) chainID() (uint64, error) {
+	c.chainMu.Lock()
+	defer c.chainMu.Unlock()
+
+	if c.cachedChainID != nil {
+		return c.cachedChainID.Uint64(), nil
+	}
+
+	chainID, err := c.Client.ChainID(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	c.cachedChainID = chainID
+	return chainID.Uint64(), nil
+}
+
+func (c *Client) GetNonce(ctx context.Context, address common.Address) (uint64, error) {
+	chainID, err := c.chainID()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ChainID: %v", err)
+	}
+
+	return c.Nonces.Acquire(chainID, address, func() (uint64, error) {
+		return c.Client.PendingNonceAt(ctx, address)
+	})
+}
+
+func (c *Client) releaseNonce(address common.Address, nonce uint64) {
+	if chainID, err := c.chainID(); err == nil {
+		c.Nonces.Release(chainID, address, nonce)
+	}
+}
+
+func (c *Client) abandonNonce(address common.Address, nonce uint64, sendErr error) {
+	if chainID, err := c.chainID(); err == nil {
+		c.Nonces.Abandon(chainID, address, nonce, sendErr)
+	}
+}
+
+func (c *Client) resyncNonce(address common.Address) {
+	if chainID, err := c.chainID(); err == nil {
+		c.Nonces.Reset(chainID, address)
+	}
+}
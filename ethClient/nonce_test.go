@@ -0,0 +1,209 @@
+package ethClient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testChainID = 8453
+
+var testAddress = common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")
+
+// fakeNode counts the pending nonce requests and answers them with pending.
+type fakeNode struct {
+	pending uint64
+	err     error
+	calls   int
+}
+
+func (n *fakeNode) fetch() (uint64, error) {
+	n.calls++
+	return n.pending, n.err
+}
+
+func acquire(t *testing.T, nm *NonceManager, node *fakeNode) uint64 {
+	t.Helper()
+	nonce, err := nm.Acquire(testChainID, testAddress, node.fetch)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	return nonce
+}
+
+func TestNonceManagerAcquireSyncsOnce(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 7}
+
+	for want := uint64(7); want < 10; want++ {
+		if got := acquire(t, nm, node); got != want {
+			t.Fatalf("nonce = %d, want %d", got, want)
+		}
+	}
+	if node.calls != 1 {
+		t.Fatalf("pending nonce fetched %d times, want 1", node.calls)
+	}
+}
+
+func TestNonceManagerSeparatesChainsAndAddresses(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 3}
+
+	acquire(t, nm, node)
+	other := common.HexToAddress("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0")
+	if got, _ := nm.Acquire(testChainID, other, node.fetch); got != 3 {
+		t.Fatalf("other address nonce = %d, want 3", got)
+	}
+	if got, _ := nm.Acquire(1, testAddress, node.fetch); got != 3 {
+		t.Fatalf("other chain nonce = %d, want 3", got)
+	}
+}
+
+func TestNonceManagerReleaseLastRewinds(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 0}
+
+	acquire(t, nm, node) // 0
+	acquire(t, nm, node) // 1
+	last := acquire(t, nm, node)
+
+	nm.Release(testChainID, testAddress, last)
+	if got := acquire(t, nm, node); got != last {
+		t.Fatalf("nonce after release = %d, want %d", got, last)
+	}
+}
+
+func TestNonceManagerReleaseGapIsReusedFirst(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 10}
+
+	for i := 0; i < 4; i++ {
+		acquire(t, nm, node) // 10..13
+	}
+	nm.Release(testChainID, testAddress, 12)
+	nm.Release(testChainID, testAddress, 11)
+
+	for _, want := range []uint64{11, 12, 14} {
+		if got := acquire(t, nm, node); got != want {
+			t.Fatalf("nonce = %d, want %d", got, want)
+		}
+	}
+}
+
+func TestNonceManagerReleaseCollapsesTrailingGaps(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 0}
+
+	for i := 0; i < 3; i++ {
+		acquire(t, nm, node) // 0..2
+	}
+	nm.Release(testChainID, testAddress, 1)
+	nm.Release(testChainID, testAddress, 2)
+
+	// 2 was the last nonce handed out, releasing it also takes back the released 1
+	if got := acquire(t, nm, node); got != 1 {
+		t.Fatalf("nonce = %d, want 1", got)
+	}
+	if got := acquire(t, nm, node); got != 2 {
+		t.Fatalf("nonce = %d, want 2", got)
+	}
+}
+
+func TestNonceManagerReleaseIgnoresUnknownNonces(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 5}
+
+	// nothing was acquired yet, so there is no state to release into
+	nm.Release(testChainID, testAddress, 2)
+	if got := acquire(t, nm, node); got != 5 {
+		t.Fatalf("nonce = %d, want 5", got)
+	}
+
+	nm.Release(testChainID, testAddress, 9)
+	if got := acquire(t, nm, node); got != 6 {
+		t.Fatalf("nonce = %d, want 6", got)
+	}
+}
+
+func TestNonceManagerResetResyncs(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 4}
+
+	acquire(t, nm, node) // 4
+	acquire(t, nm, node) // 5
+	nm.Release(testChainID, testAddress, 4)
+
+	// another tool sent transactions from the same wallet meanwhile
+	node.pending = 20
+	nm.Reset(testChainID, testAddress)
+
+	if got := acquire(t, nm, node); got != 20 {
+		t.Fatalf("nonce after reset = %d, want 20", got)
+	}
+	if got := acquire(t, nm, node); got != 21 {
+		t.Fatalf("nonce after reset = %d, want 21, released nonces must be dropped", got)
+	}
+	if node.calls != 2 {
+		t.Fatalf("pending nonce fetched %d times, want 2", node.calls)
+	}
+}
+
+func TestNonceManagerFetchErrorRetries(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 8, err: errors.New("rate limited")}
+
+	if _, err := nm.Acquire(testChainID, testAddress, node.fetch); err == nil {
+		t.Fatal("Acquire succeeded with a failing node")
+	}
+
+	node.err = nil
+	if got := acquire(t, nm, node); got != 8 {
+		t.Fatalf("nonce = %d, want 8", got)
+	}
+	if node.calls != 2 {
+		t.Fatalf("pending nonce fetched %d times, want 2", node.calls)
+	}
+}
+
+func TestNonceManagerAbandon(t *testing.T) {
+	nm := NewNonceManager()
+	node := &fakeNode{pending: 3}
+
+	// a send that never reached the chain gives its nonce back
+	nonce := acquire(t, nm, node)
+	nm.Abandon(testChainID, testAddress, nonce, errors.New("insufficient funds for gas * price + value"))
+	if got := acquire(t, nm, node); got != nonce {
+		t.Fatalf("nonce after failed send = %d, want %d", got, nonce)
+	}
+
+	// the node says the nonce is used: it must not come back, the next one is fetched anew
+	node.pending = 6
+	nm.Abandon(testChainID, testAddress, nonce, errors.New("nonce too low: next nonce 6, tx nonce 3"))
+	if got := acquire(t, nm, node); got != 6 {
+		t.Fatalf("nonce after nonce too low = %d, want 6", got)
+	}
+	if node.calls != 2 {
+		t.Fatalf("pending nonce fetched %d times, want 2", node.calls)
+	}
+}
+
+func TestNonceErrors(t *testing.T) {
+	tests := []struct {
+		err          error
+		nonce, known bool
+	}{
+		{nil, false, false},
+		{errors.New("nonce too low: next nonce 5, tx nonce 4"), true, false},
+		{errors.New("already known"), false, true},
+		{errors.New("replacement transaction underpriced"), false, false},
+	}
+	for _, tt := range tests {
+		if got := isNonceError(tt.err); got != tt.nonce {
+			t.Errorf("isNonceError(%v) = %v, want %v", tt.err, got, tt.nonce)
+		}
+		if got := isAlreadyKnown(tt.err); got != tt.known {
+			t.Errorf("isAlreadyKnown(%v) = %v, want %v", tt.err, got, tt.known)
+		}
+	}
+}
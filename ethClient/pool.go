@@ -0,0 +1,358 @@
+package ethClient
+
+import (
+	"base/httpClient"
+	"base/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	endpointCooldown    = 30 * time.Second
+	healthCheckInterval = 30 * time.Second
+	healthCheckTimeout  = 10 * time.Second
+	maxHeadLag          = 5
+)
+
+type rpcEndpoint struct {
+	URL *url.URL
+
+	mu            sync.Mutex
+	healthy       bool
+	latency       time.Duration
+	head          uint64
+	cooldownUntil time.Time
+}
+
+func (e *rpcEndpoint) available(now time.Time) (bool, time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.healthy && now.After(e.cooldownUntil), e.latency
+}
+
+// RPCPool is an http.RoundTripper that sends every JSON-RPC request to the fastest healthy
+// endpoint and moves on to the next one on timeouts, rate limits and server errors.
+type RPCPool struct {
+	Chain     string
+	endpoints []*rpcEndpoint
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	current int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewRPCPool(chain string, urls []string) (*RPCPool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints for chain %s", chain)
+	}
+
+	pool := &RPCPool{
+		Chain: chain,
+		transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+			ResponseHeaderTimeout: 20 * time.Second,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConnsPerHost:   16,
+		},
+		stop: make(chan struct{}),
+	}
+
+	for _, raw := range urls {
+		parsed, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid RPC url %q for chain %s", raw, chain)
+		}
+		pool.endpoints = append(pool.endpoints, &rpcEndpoint{URL: parsed, healthy: true})
+	}
+
+	return pool, nil
+}
+
+func (p *RPCPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// requests of a wallet with its own proxy go through it, see httpClient.WithTransport
+	transport := p.transport
+	walletTransport := false
+	if rt := httpClient.TransportFrom(req.Context()); rt != nil {
+		transport, walletTransport = rt, true
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < len(p.endpoints); attempt++ {
+		idx, endpoint := p.pick()
+
+		outReq := req.Clone(req.Context())
+		outReq.URL = endpoint.URL
+		outReq.Host = endpoint.URL.Host
+		outReq.Body = io.NopCloser(bytes.NewReader(body))
+		outReq.ContentLength = int64(len(body))
+
+		resp, err := transport.RoundTrip(outReq)
+		switch {
+		case err != nil:
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			// the wallet's proxies already tried their spares: the failure is theirs, and
+			// cooling the endpoint down would move every other wallet off a healthy RPC
+			if walletTransport {
+				return nil, fmt.Errorf("rpc %s via wallet proxy: %v", endpoint.URL.Host, err)
+			}
+			lastErr = err
+		case isRetryableStatus(resp.StatusCode):
+			lastErr = fmt.Errorf("rpc %s responded with status %d", endpoint.URL.Host, resp.StatusCode)
+			resp.Body.Close()
+		default:
+			resp, err = checkRPCBody(resp)
+			if err == nil {
+				return resp, nil
+			}
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			lastErr = fmt.Errorf("rpc %s: %v", endpoint.URL.Host, err)
+		}
+
+		p.fail(idx, lastErr)
+	}
+
+	return nil, fmt.Errorf("all RPC endpoints for %s failed: %v", p.Chain, lastErr)
+}
+
+// pick returns the available endpoint with the lowest latency seen by the health check. Endpoints
+// not measured yet come after the measured ones, in config order.
+func (p *RPCPool) pick() (int, *rpcEndpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best, bestLatency := -1, time.Duration(0)
+	for idx, endpoint := range p.endpoints {
+		ok, latency := endpoint.available(now)
+		if !ok {
+			continue
+		}
+		if best < 0 || (latency > 0 && (bestLatency == 0 || latency < bestLatency)) {
+			best, bestLatency = idx, latency
+		}
+	}
+	if best < 0 {
+		// nothing is healthy, keep trying the current one rather than failing outright
+		return p.current, p.endpoints[p.current]
+	}
+
+	p.current = best
+	return best, p.endpoints[best]
+}
+
+func (p *RPCPool) fail(idx int, reason error) {
+	endpoint := p.endpoints[idx]
+	endpoint.mu.Lock()
+	endpoint.cooldownUntil = time.Now().Add(endpointCooldown)
+	endpoint.mu.Unlock()
+
+	p.mu.Lock()
+	wasCurrent := p.current == idx
+	p.mu.Unlock()
+
+	if wasCurrent && len(p.endpoints) > 1 {
+		if next, nextEndpoint := p.pick(); next != idx {
+			logger.GlobalLogger.Warnf("RPC %s (%s) failed: %v. Switching to %s", endpoint.URL.Host, p.Chain, reason, nextEndpoint.URL.Host)
+		}
+	}
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// rpcOverloadCodes are the JSON-RPC error codes providers use for rate limits and overload.
+var rpcOverloadCodes = map[int]bool{
+	429:    true,
+	-32005: true, // limit exceeded
+	-32090: true, // too many requests
+}
+
+var rpcOverloadMarkers = []string{"rate limit", "too many requests", "request limit", "capacity exceeded", "temporarily unavailable", "service unavailable"}
+
+type rpcResponseError struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// checkRPCBody reads a 200 response and returns it with the body restored, or an error when the
+// provider answered with a rate limit or overload error in it instead of a result. Errors of the
+// calls themselves, such as reverts, are left to the caller.
+func checkRPCBody(resp *http.Response) (*http.Response, error) {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []rpcResponseError
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		json.Unmarshal(trimmed, &responses)
+	} else {
+		var single rpcResponseError
+		json.Unmarshal(trimmed, &single)
+		responses = append(responses, single)
+	}
+
+	for _, r := range responses {
+		if r.Error == nil {
+			continue
+		}
+		overloaded := rpcOverloadCodes[r.Error.Code]
+		for _, marker := range rpcOverloadMarkers {
+			overloaded = overloaded || strings.Contains(strings.ToLower(r.Error.Message), marker)
+		}
+		if overloaded {
+			return nil, fmt.Errorf("error %d: %s", r.Error.Code, r.Error.Message)
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+func (p *RPCPool) StartHealthCheck() {
+	if len(p.endpoints) < 2 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			p.checkHealth()
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *RPCPool) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func (p *RPCPool) checkHealth() {
+	var wg sync.WaitGroup
+	for _, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func(e *rpcEndpoint) {
+			defer wg.Done()
+			head, latency, err := p.probe(e)
+
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			if err != nil {
+				if e.healthy {
+					logger.GlobalLogger.Warnf("RPC %s (%s) is unhealthy: %v", e.URL.Host, p.Chain, err)
+				}
+				e.healthy = false
+				e.head = 0
+				return
+			}
+			e.head = head
+			e.latency = latency
+		}(endpoint)
+	}
+	wg.Wait()
+
+	var maxHead uint64
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		if e.head > maxHead {
+			maxHead = e.head
+		}
+		e.mu.Unlock()
+	}
+
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		if e.head > 0 {
+			lag := maxHead - e.head
+			wasHealthy := e.healthy
+			e.healthy = lag <= maxHeadLag
+			if wasHealthy && !e.healthy {
+				logger.GlobalLogger.Warnf("RPC %s (%s) is %d blocks behind, latency %v", e.URL.Host, p.Chain, lag, e.latency)
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+func (p *RPCPool) probe(e *rpcEndpoint) (uint64, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	payload := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL.String(), bytes.NewReader(payload))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	started := time.Now()
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	latency := time.Since(started)
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, 0, err
+	}
+	if result.Error != nil {
+		return 0, 0, errors.New(result.Error.Message)
+	}
+
+	head, err := strconv.ParseUint(strings.TrimPrefix(result.Result, "0x"), 16, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid block number %q", result.Result)
+	}
+
+	return head, latency, nil
+}
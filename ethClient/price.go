@@ -0,0 +1,167 @@
+package ethClient
+
+import (
+	"base/config"
+	"base/logger"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	defaultPriceTTL = time.Minute
+	// the slowest Base USD feeds (stablecoins) have a 24h heartbeat
+	chainlinkMaxAge = 25 * time.Hour
+)
+
+// PriceSource returns the USD price of one whole token.
+type PriceSource interface {
+	Price(ctx context.Context, c *Client, token common.Address) (*big.Float, error)
+}
+
+type StaticPriceSource map[common.Address]*big.Float
+
+func (s StaticPriceSource) Price(_ context.Context, _ *Client, token common.Address) (*big.Float, error) {
+	price, ok := s[token]
+	if !ok {
+		return nil, fmt.Errorf("no static price for token %s", token.Hex())
+	}
+	return new(big.Float).Set(price), nil
+}
+
+type ChainlinkPriceSource struct {
+	Feeds map[common.Address]common.Address // token -> USD aggregator
+}
+
+func (s ChainlinkPriceSource) Price(ctx context.Context, c *Client, token common.Address) (*big.Float, error) {
+	feed, ok := s.Feeds[token]
+	if !ok {
+		return nil, fmt.Errorf("no chainlink feed for token %s", token.Hex())
+	}
+
+	data, err := config.ChainlinkABI.Pack("latestRoundData")
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack latestRoundData: %v", err)
+	}
+	result, err := c.CallCA(ctx, feed, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed %s: %v", feed.Hex(), err)
+	}
+	round, err := config.ChainlinkABI.Unpack("latestRoundData", result)
+	if err != nil || len(round) < 4 {
+		return nil, fmt.Errorf("failed to unpack latestRoundData from %s: %v", feed.Hex(), err)
+	}
+
+	answer, _ := round[1].(*big.Int)
+	updatedAt, _ := round[3].(*big.Int)
+	if answer == nil || answer.Sign() <= 0 {
+		return nil, fmt.Errorf("feed %s returned invalid answer", feed.Hex())
+	}
+	if updatedAt == nil || time.Since(time.Unix(updatedAt.Int64(), 0)) > chainlinkMaxAge {
+		return nil, fmt.Errorf("feed %s is stale", feed.Hex())
+	}
+
+	data, err = config.ChainlinkABI.Pack("decimals")
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack decimals: %v", err)
+	}
+	result, err = c.CallCA(ctx, feed, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decimals of feed %s: %v", feed.Hex(), err)
+	}
+	var decimals uint8
+	if err := config.ChainlinkABI.UnpackIntoInterface(&decimals, "decimals", result); err != nil {
+		return nil, fmt.Errorf("failed to unpack decimals of feed %s: %v", feed.Hex(), err)
+	}
+
+	return scaleDown(answer, decimals), nil
+}
+
+type cachedPrice struct {
+	price     *big.Float
+	fetchedAt time.Time
+}
+
+// PriceOracle asks its sources in order, caches the first answer for TTL and falls back to the
+// static prices when every source fails.
+type PriceOracle struct {
+	Sources  []PriceSource
+	Fallback PriceSource
+	TTL      time.Duration
+
+	mu    sync.Mutex
+	cache map[common.Address]cachedPrice
+}
+
+func NewPriceOracle(ttl time.Duration, fallback PriceSource, sources ...PriceSource) *PriceOracle {
+	if ttl <= 0 {
+		ttl = defaultPriceTTL
+	}
+	return &PriceOracle{
+		Sources:  sources,
+		Fallback: fallback,
+		TTL:      ttl,
+		cache:    make(map[common.Address]cachedPrice),
+	}
+}
+
+func DefaultPriceSource() PriceSource {
+	return NewPriceOracle(defaultPriceTTL, StaticPriceSource(config.Chains.FallbackPrices))
+}
+
+func (o *PriceOracle) Price(ctx context.Context, c *Client, token common.Address) (*big.Float, error) {
+	o.mu.Lock()
+	cached, ok := o.cache[token]
+	o.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < o.TTL {
+		return new(big.Float).Set(cached.price), nil
+	}
+
+	var errs []error
+	for _, source := range o.Sources {
+		price, err := source.Price(ctx, c, token)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		o.store(token, price)
+		return new(big.Float).Set(price), nil
+	}
+
+	// a stopped account must not fall back to static prices
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if o.Fallback != nil {
+		price, err := o.Fallback.Price(ctx, c, token)
+		if err == nil {
+			if len(errs) > 0 {
+				logger.GlobalLogger.Warnf("Using static price for token %s: %v", token.Hex(), errors.Join(errs...))
+			}
+			// cached as well, so a dead feed is not hit on every balance check
+			o.store(token, price)
+			return price, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("price not found for token %s: %v", token.Hex(), errors.Join(errs...))
+}
+
+func (o *PriceOracle) store(token common.Address, price *big.Float) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cache[token] = cachedPrice{price: new(big.Float).Set(price), fetchedAt: time.Now()}
+}
+
+func scaleDown(amount *big.Int, decimals uint8) *big.Float {
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(divisor))
+}
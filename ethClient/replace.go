@@ -0,0 +1,296 @@
+package ethClient
+
+import (
+	"base/account"
+	"base/config"
+	"base/logger"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	receiptPollInterval = 3 * time.Second
+	minBumpPercent      = 10
+)
+
+type ReplacementPolicy struct {
+	ReplaceAfter    time.Duration
+	BumpPercent     int64
+	MaxReplacements int
+	Cancel          bool
+	MaxWait         time.Duration
+}
+
+func DefaultReplacementPolicy() ReplacementPolicy {
+	return ReplacementPolicy{
+		ReplaceAfter:    time.Minute,
+		BumpPercent:     15,
+		MaxReplacements: 3,
+		Cancel:          true,
+		MaxWait:         10 * time.Minute,
+	}
+}
+
+func NewReplacementPolicy(cfg config.TxConfig) ReplacementPolicy {
+	policy := DefaultReplacementPolicy()
+	if cfg.ReplaceAfterSec > 0 {
+		policy.ReplaceAfter = time.Duration(cfg.ReplaceAfterSec) * time.Second
+	}
+	if cfg.BumpPercent > 0 {
+		policy.BumpPercent = cfg.BumpPercent
+	}
+	if policy.BumpPercent < minBumpPercent {
+		// nodes reject replacements that bump the fees by less than 10%
+		policy.BumpPercent = minBumpPercent + 1
+	}
+	if cfg.MaxReplacements != nil {
+		policy.MaxReplacements = *cfg.MaxReplacements
+	}
+	if cfg.Cancel != nil {
+		policy.Cancel = *cfg.Cancel
+	}
+	if cfg.MaxWaitSec > 0 {
+		policy.MaxWait = time.Duration(cfg.MaxWaitSec) * time.Second
+	}
+	return policy
+}
+
+type trackedTx struct {
+	ctx          context.Context
+	acc          *account.Account
+	nonce        uint64
+	original     common.Hash
+	initial      *types.Transaction
+	current      *types.Transaction
+	hashes       []common.Hash
+	replacements int
+	cancelHash   common.Hash
+	lastSent     time.Time
+	capped       bool
+}
+
+// track keeps the values of ctx, such as the wallet's proxy, but not its cancellation: a
+// broadcast transaction is followed until it is mined even after a stop signal.
+func (c *Client) track(ctx context.Context, acc *account.Account, tx *types.Transaction) *trackedTx {
+	tracked := &trackedTx{
+		ctx:      context.WithoutCancel(ctx),
+		acc:      acc,
+		nonce:    tx.Nonce(),
+		original: tx.Hash(),
+		initial:  tx,
+		current:  tx,
+		hashes:   []common.Hash{tx.Hash()},
+		lastSent: time.Now(),
+	}
+	c.Txs.Store(tx.Hash(), tracked)
+	return tracked
+}
+
+func (c *Client) untrack(tracked *trackedTx) {
+	for _, hash := range tracked.hashes {
+		c.Txs.Delete(hash)
+	}
+}
+
+// waitForTransaction polls receipts for every hash broadcast with the tracked nonce. Once the
+// policy deadline passes, the transaction is re-broadcast with bumped fees and, as a last
+// resort, replaced with a zero-value self-transfer.
+func (c *Client) waitForTransaction(tracked *trackedTx) (*types.Receipt, error) {
+	defer c.untrack(tracked)
+
+	policy := c.Replacement
+	started := time.Now()
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		receipt, err := c.findReceipt(tracked)
+		if err != nil {
+			// a rate limit or a lost connection says nothing about the transaction, keep polling
+			// until MaxWait; tracked.ctx is never cancelled, a broadcast transaction is followed
+			// through shutdown
+			logger.GlobalLogger.Warnf("Transaction %s: %v, retrying...", tracked.current.Hash().Hex(), err)
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+
+		if time.Since(started) > policy.MaxWait {
+			return nil, fmt.Errorf("transaction wait timeout, nonce %d still pending (last hash %s)", tracked.nonce, tracked.current.Hash().Hex())
+		}
+
+		// once the fee limit is reached the transaction can only be waited for
+		if tracked.capped || time.Since(tracked.lastSent) < policy.ReplaceAfter {
+			continue
+		}
+
+		switch {
+		case tracked.replacements < policy.MaxReplacements:
+			if err := c.replace(tracked, false); err != nil {
+				logger.GlobalLogger.Warnf("Failed to speed up transaction %s: %v", tracked.current.Hash().Hex(), err)
+			}
+		case policy.Cancel && tracked.cancelHash == (common.Hash{}):
+			if err := c.replace(tracked, true); err != nil {
+				logger.GlobalLogger.Warnf("Failed to cancel transaction %s: %v", tracked.current.Hash().Hex(), err)
+			}
+		}
+	}
+}
+
+func (c *Client) findReceipt(tracked *trackedTx) (*types.Receipt, error) {
+	for _, hash := range tracked.hashes {
+		receipt, err := c.Client.TransactionReceipt(tracked.ctx, hash)
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("error getting transaction receipt: %v", err)
+		}
+
+		if hash != tracked.original {
+			logger.GlobalLogger.Infof("Transaction %s landed instead of the original %s", hash.Hex(), tracked.original.Hex())
+		}
+		return receipt, nil
+	}
+
+	logger.GlobalLogger.Infof("Transaction %s not yet found in the blockchain, retrying...", tracked.current.Hash().Hex())
+	return nil, nil
+}
+
+func (c *Client) replace(tracked *trackedTx, cancel bool) error {
+	prev := tracked.current
+
+	var (
+		to    = prev.To()
+		value = prev.Value()
+		data  = prev.Data()
+		gas   = prev.Gas()
+	)
+	if cancel {
+		to = &tracked.acc.Address
+		value = big.NewInt(0)
+		data = nil
+		gas = params.TxGas
+	}
+
+	tip := bumpFee(prev.GasTipCap(), c.Replacement.BumpPercent)
+	feeCap := bumpFee(prev.GasFeeCap(), c.Replacement.BumpPercent)
+	if tip.Cmp(feeCap) > 0 {
+		feeCap = new(big.Int).Set(tip)
+	}
+
+	// nodes reject a replacement below the minimum bump, so past the limit there is nothing to send
+	limit := c.feeCapLimit(tracked.acc)
+	if limit != nil && feeCap.Cmp(limit) > 0 {
+		tracked.capped = true
+		logger.GlobalLogger.Warnf("Transaction %s is stuck, but a replacement needs a fee cap of %s wei, above the limit of %s wei. Waiting for it without replacing", tracked.original.Hex(), feeCap, limit)
+		return nil
+	}
+
+	if fees, err := c.Gas.Fees(tracked.ctx, c); err == nil {
+		if fees.Tip.Cmp(tip) > 0 {
+			tip = fees.Tip
+		}
+		if fees.FeeCap.Cmp(feeCap) > 0 {
+			feeCap = fees.FeeCap
+		}
+	}
+	if limit != nil {
+		if feeCap.Cmp(limit) > 0 {
+			feeCap = new(big.Int).Set(limit)
+		}
+		if tip.Cmp(feeCap) > 0 {
+			tip = new(big.Int).Set(feeCap)
+		}
+	}
+	if tip.Cmp(feeCap) > 0 {
+		feeCap = new(big.Int).Set(tip)
+	}
+
+	chainID, err := c.chainID()
+	if err != nil {
+		return err
+	}
+
+	replacement, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     tracked.nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	}), types.LatestSignerForChainID(new(big.Int).SetUint64(chainID)), tracked.acc.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign replacement: %v", err)
+	}
+
+	tracked.lastSent = time.Now()
+	tracked.replacements++
+
+	if err := c.Client.SendTransaction(tracked.ctx, replacement); err != nil && !isAlreadyKnown(err) {
+		if isNonceError(err) {
+			// one of the already broadcast hashes has been mined, the next poll will pick it up
+			return nil
+		}
+		if strings.Contains(strings.ToLower(err.Error()), "underpriced") {
+			// keep the bumped fees so the next attempt goes higher
+			tracked.current = replacement
+		}
+		return err
+	}
+
+	tracked.current = replacement
+	tracked.hashes = append(tracked.hashes, replacement.Hash())
+	c.Txs.Store(replacement.Hash(), tracked)
+
+	if cancel {
+		tracked.cancelHash = replacement.Hash()
+		logger.GlobalLogger.Warnf("Transaction %s is stuck, cancel sent: https://basescan.org/tx/%s", tracked.original.Hex(), replacement.Hash().Hex())
+	} else {
+		logger.GlobalLogger.Warnf("Transaction %s is stuck, speed-up #%d sent: https://basescan.org/tx/%s", tracked.original.Hex(), tracked.replacements, replacement.Hash().Hex())
+	}
+	return nil
+}
+
+// feeCapLimit is the lowest of the account's max gas price and the fee cap of the gas strategy,
+// or nil when neither is set.
+func (c *Client) feeCapLimit(acc *account.Account) *big.Int {
+	var limit *big.Int
+	for _, value := range []*big.Int{acc.MaxGasPrice, strategyFeeCap(c.Gas)} {
+		if value != nil && value.Sign() > 0 && (limit == nil || value.Cmp(limit) < 0) {
+			limit = value
+		}
+	}
+	return limit
+}
+
+func strategyFeeCap(strategy GasStrategy) *big.Int {
+	switch s := strategy.(type) {
+	case FixedStrategy:
+		return s.FeeCap
+	case BaseFeeMultiplierStrategy:
+		return s.MaxFeeCap
+	case FeeHistoryStrategy:
+		return s.MaxFeeCap
+	}
+	return nil
+}
+
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Div(bumped, big.NewInt(100))
+	return bumped.Add(bumped, big.NewInt(1))
+}
@@ -0,0 +1,99 @@
+package ethClient
+
+import (
+	"base/account"
+	"base/logger"
+	"base/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const dryRunFallbackGas = 1_000_000
+
+// simulateTransaction builds and signs the transaction exactly like SendTransaction but only
+// runs it through eth_estimateGas and eth_call against the latest block.
+func (c *Client) simulateTransaction(ctx context.Context, acc *account.Account, CA common.Address, value *big.Int, txData []byte) error {
+	chainID, err := c.chainID()
+	if err != nil {
+		return fmt.Errorf("failed to get ChainID: %v", err)
+	}
+
+	msg := ethereum.CallMsg{
+		From:  acc.Address,
+		To:    &CA,
+		Value: value,
+		Data:  txData,
+	}
+
+	fees, err := c.Gas.Fees(ctx, c)
+	if err != nil {
+		return fmt.Errorf("failed to get gas fees: %v", err)
+	}
+	if acc.MaxGasPrice != nil && fees.EffectivePrice().Cmp(acc.MaxGasPrice) > 0 {
+		logger.GlobalLogger.Warnf("[DRY-RUN] gas price %s wei is above the account limit %s wei, a real run would wait", fees.EffectivePrice(), acc.MaxGasPrice)
+	}
+
+	var revertReason string
+	gasLimit, err := c.Client.EstimateGas(ctx, msg)
+	if err != nil {
+		revertReason = decodeRevert(err)
+		gasLimit = dryRunFallbackGas
+	}
+
+	nonce, err := c.Client.PendingNonceAt(ctx, acc.Address)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %v", err)
+	}
+
+	signedTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainID),
+		Nonce:     nonce,
+		GasTipCap: fees.Tip,
+		GasFeeCap: fees.FeeCap,
+		Gas:       gasLimit,
+		To:        &CA,
+		Value:     value,
+		Data:      txData,
+	}), types.LatestSignerForChainID(new(big.Int).SetUint64(chainID)), acc.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %v", err)
+	}
+
+	result, callErr := c.Client.CallContract(ctx, msg, nil)
+	if callErr != nil && revertReason == "" {
+		revertReason = decodeRevert(callErr)
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), fees.EffectivePrice())
+
+	logger.GlobalLogger.Infof("[DRY-RUN] account %d: %s -> %s", acc.AccountID, acc.Address.Hex(), CA.Hex())
+	logger.GlobalLogger.Infof("[DRY-RUN]   call: %s", utils.DecodeCall(txData))
+	logger.GlobalLogger.Infof("[DRY-RUN]   value: %s wei, nonce: %d, gas: %d, est. fee: %s wei, hash: %s (not broadcast)", value, nonce, gasLimit, fee, signedTx.Hash().Hex())
+	if revertReason != "" {
+		logger.GlobalLogger.Warnf("[DRY-RUN]   reverted: %s", revertReason)
+	} else {
+		logger.GlobalLogger.Infof("[DRY-RUN]   success, result: 0x%x", result)
+	}
+
+	return nil
+}
+
+func decodeRevert(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if reason, unpackErr := abi.UnpackRevert(common.FromHex(data)); unpackErr == nil {
+				return reason
+			}
+		}
+	}
+	return err.Error()
+}
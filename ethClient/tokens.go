@@ -0,0 +1,185 @@
+package ethClient
+
+import (
+	"base/config"
+	"base/logger"
+	"base/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const nativeDecimals = 18
+
+type TokenMeta struct {
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// TokenRegistry resolves token names from the config and keeps decimals and symbols fetched
+// on-chain in a per-chain cache, which is persisted to disk when a path is set.
+type TokenRegistry struct {
+	mu     sync.RWMutex
+	path   string
+	names  map[string]common.Address
+	tokens map[string]map[common.Address]TokenMeta // chain -> token -> meta
+}
+
+var DefaultTokenRegistry = &TokenRegistry{
+	names:  make(map[string]common.Address),
+	tokens: make(map[string]map[common.Address]TokenMeta),
+}
+
+func NewTokenRegistry(cachePath string, names map[string]common.Address) (*TokenRegistry, error) {
+	r := &TokenRegistry{
+		path:   cachePath,
+		names:  make(map[string]common.Address, len(names)),
+		tokens: make(map[string]map[common.Address]TokenMeta),
+	}
+
+	for name, addr := range names {
+		r.names[strings.ToLower(name)] = addr
+	}
+
+	if cachePath == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("failed to read token cache: %v", err)
+	}
+	if err := json.Unmarshal(data, &r.tokens); err != nil {
+		logger.GlobalLogger.Warnf("Token cache %s is corrupted and will be rebuilt: %v", cachePath, err)
+		r.tokens = make(map[string]map[common.Address]TokenMeta)
+	}
+
+	return r, nil
+}
+
+// Address returns the address configured for a token name such as "usdc".
+func (r *TokenRegistry) Address(name string) (common.Address, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	addr, ok := r.names[strings.ToLower(name)]
+	return addr, ok
+}
+
+// Resolve accepts either a configured token name or a hex address.
+func (r *TokenRegistry) Resolve(key string) (common.Address, bool) {
+	if common.IsHexAddress(key) {
+		return common.HexToAddress(key), true
+	}
+	return r.Address(key)
+}
+
+// Addresses resolves a list of token names, failing on the first unknown one.
+func (r *TokenRegistry) Addresses(names []string) ([]common.Address, error) {
+	addrs := make([]common.Address, 0, len(names))
+	for _, name := range names {
+		addr, ok := r.Address(name)
+		if !ok {
+			return nil, fmt.Errorf("token %s is not defined in config tokens", name)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func (r *TokenRegistry) cached(chain string, token common.Address) (TokenMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.tokens[chain][token]
+	return meta, ok
+}
+
+func (r *TokenRegistry) store(chain string, token common.Address, meta TokenMeta) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens[chain] == nil {
+		r.tokens[chain] = make(map[common.Address]TokenMeta)
+	}
+	r.tokens[chain][token] = meta
+
+	if r.path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(r.tokens, "", "  ")
+	if err != nil {
+		logger.GlobalLogger.Warnf("Failed to encode token cache: %v", err)
+		return
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		logger.GlobalLogger.Warnf("Failed to write token cache %s: %v", r.path, err)
+	}
+}
+
+// TokenMeta returns the token's decimals and symbol, reading them from the contract the first
+// time the token is seen on this chain.
+func (c *Client) TokenMeta(ctx context.Context, token common.Address) (TokenMeta, error) {
+	if utils.IsNativeToken(token) {
+		return TokenMeta{Symbol: "ETH", Decimals: nativeDecimals}, nil
+	}
+
+	if meta, ok := c.Tokens.cached(c.Chain, token); ok {
+		return meta, nil
+	}
+
+	meta, err := c.fetchTokenMeta(ctx, token)
+	if err != nil {
+		return TokenMeta{}, err
+	}
+
+	c.Tokens.store(c.Chain, token, meta)
+	return meta, nil
+}
+
+func (c *Client) Decimals(ctx context.Context, token common.Address) (uint8, error) {
+	meta, err := c.TokenMeta(ctx, token)
+	if err != nil {
+		return 0, err
+	}
+	return meta.Decimals, nil
+}
+
+func (c *Client) fetchTokenMeta(ctx context.Context, token common.Address) (TokenMeta, error) {
+	var meta TokenMeta
+
+	data, err := config.Erc20ABI.Pack("decimals")
+	if err != nil {
+		return meta, fmt.Errorf("failed to pack decimals: %v", err)
+	}
+	result, err := c.CallCA(ctx, token, data)
+	if err != nil {
+		return meta, fmt.Errorf("failed to get decimals for token %s: %v", token.Hex(), err)
+	}
+	if err := config.Erc20ABI.UnpackIntoInterface(&meta.Decimals, "decimals", result); err != nil {
+		return meta, fmt.Errorf("failed to unpack decimals for token %s: %v", token.Hex(), err)
+	}
+
+	data, err = config.Erc20ABI.Pack("symbol")
+	if err != nil {
+		return meta, fmt.Errorf("failed to pack symbol: %v", err)
+	}
+	result, err = c.CallCA(ctx, token, data)
+	if err != nil {
+		return meta, fmt.Errorf("failed to get symbol for token %s: %v", token.Hex(), err)
+	}
+	if err := config.Erc20ABI.UnpackIntoInterface(&meta.Symbol, "symbol", result); err != nil {
+		// some old tokens return the symbol as bytes32
+		meta.Symbol = strings.TrimRight(string(result), "\x00")
+	}
+
+	return meta, nil
+}
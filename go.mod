@@ -44,8 +44,8 @@ require (
 	github.com/rogpeppe/go-internal v1.12.0 // indirect
 	github.com/status-im/keycard-go v0.2.0 // indirect
 	github.com/syndtr/goleveldb v1.0.1-0.20210819022825-2ae1ddf74ef7 // indirect
-	github.com/tyler-smith/go-bip39 v1.1.0 // indirect
-	golang.org/x/text v0.19.0 // indirect
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/text v0.19.0
 	google.golang.org/protobuf v1.34.2 // indirect
 )
 
@@ -71,9 +71,9 @@ require (
 	github.com/supranational/blst v0.3.13 // indirect
 	github.com/tklauser/go-sysconf v0.3.12 // indirect
 	github.com/tklauser/numcpus v0.6.1 // indirect
-	golang.org/x/crypto v0.28.0 // indirect
+	golang.org/x/crypto v0.28.0
 	golang.org/x/exp v0.0.0-20231110203233-9a3e6036ecaa // indirect
-	golang.org/x/sync v0.9.0 // indirect
+	golang.org/x/sync v0.9.0
 	golang.org/x/sys v0.26.0 // indirect
 	rsc.io/tmplfunc v0.0.3 // indirect
 )
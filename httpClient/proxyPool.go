@@ -0,0 +1,332 @@
+package httpClient
+
+import (
+	"base/logger"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultProxyCheckURL = "https://api.ipify.org"
+	DefaultProxyCooldown = 5 * time.Minute
+
+	proxyCheckTimeout = 15 * time.Second
+)
+
+type transportKey struct{}
+
+// WithTransport makes RPC requests sent with ctx go through rt, see ethClient.RPCPool.
+func WithTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportKey{}, rt)
+}
+
+// TransportFrom returns the transport set with WithTransport, or nil.
+func TransportFrom(ctx context.Context) http.RoundTripper {
+	rt, _ := ctx.Value(transportKey{}).(http.RoundTripper)
+	return rt
+}
+
+// ParseProxy checks a proxy url: http, https, socks5 or socks5h with a host.
+func ParseProxy(raw string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %v", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy %s: scheme must be http, https, socks5 or socks5h", parsed.Redacted())
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("proxy %s has no host", parsed.Redacted())
+	}
+	return parsed, nil
+}
+
+type proxy struct {
+	URL       *url.URL
+	transport *http.Transport
+
+	mu            sync.Mutex
+	healthy       bool
+	cooldownUntil time.Time
+	exitIP        string
+}
+
+func (p *proxy) available(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.healthy && now.After(p.cooldownUntil)
+}
+
+// ProxyPool keeps one transport per proxy, shared by every wallet that uses it. A proxy that
+// fails a request or a health check is skipped until its cooldown ends, and the wallet's
+// requests go through its next proxy meanwhile.
+type ProxyPool struct {
+	checkURL string
+	cooldown time.Duration
+	fallback []string
+
+	mu      sync.Mutex
+	proxies map[string]*proxy
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewProxyPool creates a pool. fallback is the proxy of wallets that have none of their own,
+// empty means they connect directly.
+func NewProxyPool(fallback, checkURL string, cooldown time.Duration) (*ProxyPool, error) {
+	if checkURL == "" {
+		checkURL = DefaultProxyCheckURL
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultProxyCooldown
+	}
+
+	pool := &ProxyPool{
+		checkURL: checkURL,
+		cooldown: cooldown,
+		proxies:  make(map[string]*proxy),
+		stop:     make(chan struct{}),
+	}
+	if fallback = strings.TrimSpace(fallback); fallback != "" {
+		if err := pool.Add(fallback); err != nil {
+			return nil, err
+		}
+		pool.fallback = []string{fallback}
+	}
+	return pool, nil
+}
+
+// Add registers proxies, so bad urls are reported before the first request.
+func (p *ProxyPool) Add(proxies ...string) error {
+	for _, raw := range proxies {
+		if _, err := p.get(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProxyPool) get(raw string) (*proxy, error) {
+	key := strings.TrimSpace(raw)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if px, ok := p.proxies[key]; ok {
+		return px, nil
+	}
+
+	parsed, err := ParseProxy(key)
+	if err != nil {
+		return nil, err
+	}
+	px := &proxy{
+		URL: parsed,
+		transport: &http.Transport{
+			Proxy:                 http.ProxyURL(parsed),
+			DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 20 * time.Second,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConnsPerHost:   4,
+		},
+		healthy: true,
+	}
+	p.proxies[key] = px
+	return px, nil
+}
+
+// Transport returns a RoundTripper that sends requests through the first available proxy of
+// the list, in order, so a wallet keeps its exit IP while its first proxy works. A wallet
+// without proxies uses the fallback proxy; with no fallback either the transport is nil and
+// requests go out directly.
+func (p *ProxyPool) Transport(proxies []string) http.RoundTripper {
+	if len(proxies) == 0 {
+		proxies = p.fallback
+	}
+	if len(proxies) == 0 {
+		return nil
+	}
+	return &proxyTransport{pool: p, proxies: proxies}
+}
+
+// Client returns an HttpClient for a wallet's proxies.
+func (p *ProxyPool) Client(proxies []string) *HttpClient {
+	return &HttpClient{Client: &http.Client{Transport: p.Transport(proxies)}}
+}
+
+// order returns the available proxies first, keeping the list order, then the rest: with
+// nothing healthy the requests are still tried rather than failed outright.
+func (p *ProxyPool) order(proxies []string) ([]*proxy, error) {
+	now := time.Now()
+	var available, rest []*proxy
+	for _, raw := range proxies {
+		px, err := p.get(raw)
+		if err != nil {
+			return nil, err
+		}
+		if px.available(now) {
+			available = append(available, px)
+		} else {
+			rest = append(rest, px)
+		}
+	}
+	return append(available, rest...), nil
+}
+
+func (p *ProxyPool) fail(px *proxy, reason error) {
+	px.mu.Lock()
+	defer px.mu.Unlock()
+
+	now := time.Now()
+	if now.After(px.cooldownUntil) {
+		logger.GlobalLogger.Warnf("Proxy %s failed: %v. Skipping it for %v", px.URL.Redacted(), reason, p.cooldown)
+	}
+	px.cooldownUntil = now.Add(p.cooldown)
+}
+
+type proxyTransport struct {
+	pool    *ProxyPool
+	proxies []string
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	proxies, err := t.pool.order(t.proxies)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, px := range proxies {
+		outReq := req.Clone(req.Context())
+		if req.Body != nil {
+			outReq.Body = io.NopCloser(bytes.NewReader(body))
+			outReq.ContentLength = int64(len(body))
+		}
+
+		resp, err := px.transport.RoundTrip(outReq)
+		if err == nil {
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+		t.pool.fail(px, err)
+	}
+
+	return nil, fmt.Errorf("all proxies failed: %v", lastErr)
+}
+
+// Check requests the check url through every proxy and logs the exit IP of the working ones.
+// It returns the number of proxies that failed.
+func (p *ProxyPool) Check() int {
+	p.mu.Lock()
+	proxies := make([]*proxy, 0, len(p.proxies))
+	for _, px := range p.proxies {
+		proxies = append(proxies, px)
+	}
+	p.mu.Unlock()
+
+	var (
+		wg     sync.WaitGroup
+		failed int
+		mu     sync.Mutex
+	)
+	for _, px := range proxies {
+		wg.Add(1)
+		go func(px *proxy) {
+			defer wg.Done()
+			ip, err := p.probe(px)
+
+			px.mu.Lock()
+			defer px.mu.Unlock()
+			if err != nil {
+				if px.healthy {
+					logger.GlobalLogger.Warnf("Proxy %s is unhealthy: %v", px.URL.Redacted(), err)
+				}
+				px.healthy = false
+				mu.Lock()
+				failed++
+				mu.Unlock()
+				return
+			}
+			if !px.healthy || px.exitIP != ip {
+				logger.GlobalLogger.Infof("Proxy %s is up, exit IP %s", px.URL.Redacted(), ip)
+			}
+			px.healthy = true
+			px.exitIP = ip
+		}(px)
+	}
+	wg.Wait()
+	return failed
+}
+
+// StartHealthCheck re-checks the proxies every interval, bringing back the ones that recover.
+func (p *ProxyPool) StartHealthCheck(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.Check()
+			}
+		}
+	}()
+}
+
+func (p *ProxyPool) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func (p *ProxyPool) probe(px *proxy) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), proxyCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.checkURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := px.transport.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -3,6 +3,7 @@ package bridge
 import (
 	"base/ethClient"
 	"base/models"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -10,7 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-func getFee(client *ethClient.Client, toCA common.Address, abi *abi.ABI, ownerAddr common.Address, dstChain uint16, methodName string) (*big.Int, error) {
+func getFee(ctx context.Context, client *ethClient.Client, toCA common.Address, abi *abi.ABI, ownerAddr common.Address, dstChain uint16, methodName string) (*big.Int, error) {
 	feeData, err := abi.Pack("quoteLayerZeroFee", dstChain, uint8(1), ownerAddr.Bytes(), []byte{}, models.LzTxObj{
 		DstGasForCall:   big.NewInt(0),
 		DstNativeAmount: big.NewInt(0),
@@ -20,7 +21,7 @@ func getFee(client *ethClient.Client, toCA common.Address, abi *abi.ABI, ownerAd
 		return nil, fmt.Errorf("failed pack data for quoteLayerZeroFee: %v", err)
 	}
 
-	feeResult, err := client.CallCA(toCA, feeData)
+	feeResult, err := client.CallCA(ctx, toCA, feeData)
 	if err != nil {
 		return nil, fmt.Errorf("failed call quoteLayerZeroFee: %v", err)
 	}
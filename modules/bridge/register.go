@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"base/modules"
+	"base/utils"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StargateModule is the name the Stargate bridge is registered under.
+const StargateModule = "stargate"
+
+func init() {
+	modules.Register(StargateModule, func(deps modules.Deps) (any, error) {
+		cfg := deps.Config
+		swap_abi, err := utils.ReadAbi(cfg.BridgeConfig.SwapABIPath)
+		if err != nil {
+			return nil, err
+		}
+
+		fee_abi, err := utils.ReadAbi(cfg.BridgeConfig.FeeABIPath)
+		if err != nil {
+			return nil, err
+		}
+
+		swap_cas := make(map[string]common.Address)
+		fee_cas := make(map[string]common.Address)
+		for _, name := range cfg.Chains.Names() {
+			chain, _ := cfg.Chains.Chain(name)
+			if chain.StargateRouter == (common.Address{}) {
+				continue
+			}
+			swap_cas[name] = chain.StargateRouter
+			fee_cas[name] = chain.StargateFeeQuoter
+		}
+
+		// keyed by the chain names of config.Chains and the lower-case token name
+		pool_ids := map[string]*big.Int{
+			"ethereum_usdc": big.NewInt(1),
+			"ethereum_usdt": big.NewInt(2),
+			"ethereum_dai":  big.NewInt(3),
+			"ethereum_eth":  big.NewInt(13),
+
+			"bsc_usdt": big.NewInt(2),
+
+			"avalanche_usdc": big.NewInt(1),
+			"avalanche_usdt": big.NewInt(2),
+
+			"polygon_usdc": big.NewInt(1),
+			"polygon_usdt": big.NewInt(2),
+
+			"arbitrum_usdc": big.NewInt(1),
+			"arbitrum_usdt": big.NewInt(2),
+			"arbitrum_eth":  big.NewInt(13),
+
+			"optimism_usdc": big.NewInt(1),
+			"optimism_dai":  big.NewInt(3),
+			"optimism_eth":  big.NewInt(13),
+
+			"base_usdc": big.NewInt(1),
+			"base_eth":  big.NewInt(13),
+		}
+
+		chain_ids := map[string]uint16{
+			"ethereum":  101,
+			"bnb":       102,
+			"avalanche": 106,
+			"polygon":   109,
+			"arbitrum":  110,
+			"optimism":  111,
+			"fantom":    112,
+			"base":      184,
+			"linea":     183,
+		}
+		return NewStargate(deps.Clients, swap_cas, fee_cas, pool_ids, chain_ids, swap_abi, fee_abi)
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"base/account"
 	"base/ethClient"
 	"base/models"
+	"context"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -33,8 +35,13 @@ func NewStargate(client map[string]*ethClient.Client, swapCAs, feeCAs map[string
 	}, nil
 }
 
-func (stg *Stargate) SwapStable(from, dstChain, token string, amountIn *big.Int, acc *account.Account) error {
-	fee, err := getFee(stg.Clients[from], stg.FeeCAs[from], stg.FeeABI, acc.Address, stg.ChainIDs[dstChain], "quoteLayerZeroFee")
+func (stg *Stargate) SwapStable(ctx context.Context, from, dstChain, token string, amountIn *big.Int, acc *account.Account) error {
+	srcPoolID, ok := stg.PoolsIds[fmt.Sprintf("%s_%s", from, strings.ToLower(token))]
+	if !ok {
+		return fmt.Errorf("stargate has no %s pool on %s", token, from)
+	}
+
+	fee, err := getFee(ctx, stg.Clients[from], stg.FeeCAs[from], stg.FeeABI, acc.Address, stg.ChainIDs[dstChain], "quoteLayerZeroFee")
 	if err != nil {
 		return err
 	}
@@ -43,7 +50,7 @@ func (stg *Stargate) SwapStable(from, dstChain, token string, amountIn *big.Int,
 	swapData, err := stg.SwapABI.Pack(
 		"swap",
 		stg.ChainIDs[dstChain],
-		stg.PoolsIds[fmt.Sprintf("%s_%s", from, token)],
+		srcPoolID,
 		stg.PoolsIds["base_usdc"],
 		acc.Address,
 		amountIn,
@@ -60,5 +67,5 @@ func (stg *Stargate) SwapStable(from, dstChain, token string, amountIn *big.Int,
 		return fmt.Errorf("failed pack data for stargate: %v", err)
 	}
 
-	return stg.Clients[from].SendTransaction(acc.PrivateKey, acc.Address, stg.SwapCAs[from], stg.Clients[from].GetNonce(acc.Address), fee, swapData)
+	return stg.Clients[from].SendTransaction(ctx, acc, stg.SwapCAs[from], fee, swapData)
 }
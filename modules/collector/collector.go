@@ -8,6 +8,8 @@ import (
 	"base/modules/dex"
 	"base/modules/liquid_pools/aave"
 	"base/modules/liquid_pools/moonwell"
+	"base/utils"
+	"context"
 	"fmt"
 	"math/big"
 	"time"
@@ -27,11 +29,11 @@ type Collector struct {
 func NewCollector(client *ethClient.Client, dex *dex.V3Router, aave *aave.Aave, moonwell *moonwell.Moonwell) *Collector {
 	return &Collector{
 		availableTokens: []TokenInfo{
-			{Address: config.USDC, Type: ERC20, RequiresPrice: true},
-			{Address: config.USDbC, Type: ERC20, RequiresPrice: true},
-			{Address: config.AaveUSDC, Type: AaveLiquidityPool, Pool: aave, RequiresPrice: false},
-			{Address: config.AaveWETH, Type: AaveLiquidityPool, Pool: aave, RequiresPrice: false},
-			{Address: config.MoonwellWETH, Type: MoonwellLiquidityPool, Pool: moonwell, RequiresPrice: false},
+			{Address: config.Chains.Base.USDC, Type: ERC20, RequiresPrice: true},
+			{Address: config.Chains.Base.USDbC, Type: ERC20, RequiresPrice: true},
+			{Address: config.Chains.Base.AaveUSDC, Type: AaveLiquidityPool, Pool: aave, RequiresPrice: false},
+			{Address: config.Chains.Base.AaveWETH, Type: AaveLiquidityPool, Pool: aave, RequiresPrice: false},
+			{Address: config.Chains.Base.MoonwellWETH, Type: MoonwellLiquidityPool, Pool: moonwell, RequiresPrice: false},
 		},
 		Client:        client,
 		Dex:           dex,
@@ -41,20 +43,32 @@ func NewCollector(client *ethClient.Client, dex *dex.V3Router, aave *aave.Aave,
 	}
 }
 
-func (c *Collector) Collect(acc *account.Account) error {
+func (c *Collector) Collect(ctx context.Context, acc *account.Account) error {
 	logger.GlobalLogger.Infof("Начало сбора для аккаунта: %s", acc.Address.Hex())
 
+	tokens := make([]common.Address, len(c.availableTokens))
+	for i, tokenInfo := range c.availableTokens {
+		tokens[i] = tokenInfo.Address
+	}
+
+	// swaps and withdrawals below only touch tokens that come earlier in the list,
+	// so the balances read up front stay valid for the whole pass
+	balances, err := c.Client.BatchBalances(ctx, acc.Address, tokens)
+	if err != nil {
+		return fmt.Errorf("ошибка получения балансов: %v", err)
+	}
+
 	for _, tokenInfo := range c.availableTokens {
 		token := tokenInfo.Address
 
 		switch tokenInfo.Type {
 		case ERC20:
-			if err := c.processERC20Token(acc, tokenInfo); err != nil {
+			if err := c.processERC20Token(ctx, acc, tokenInfo, balances); err != nil {
 				logger.GlobalLogger.Error(err)
 				continue
 			}
 		case AaveLiquidityPool, MoonwellLiquidityPool:
-			if err := c.processLiquidityPoolToken(acc, tokenInfo); err != nil {
+			if err := c.processLiquidityPoolToken(ctx, acc, tokenInfo, balances); err != nil {
 				logger.GlobalLogger.Error(err)
 				continue
 			}
@@ -64,13 +78,13 @@ func (c *Collector) Collect(acc *account.Account) error {
 	}
 
 	if acc.RevertAllowance {
-		if err := c.rollbackAllowances(acc); err != nil {
+		if err := c.rollbackAllowances(ctx, acc); err != nil {
 			logger.GlobalLogger.Errorf("Ошибка при откате allowances: %v", err)
 		}
 	}
 
 	if acc.Endpoint != (common.Address{}) {
-		if err := c.transferTokens(acc); err != nil {
+		if err := c.transferTokens(ctx, acc); err != nil {
 			return err
 		}
 	}
@@ -79,13 +93,13 @@ func (c *Collector) Collect(acc *account.Account) error {
 	return nil
 }
 
-func (c *Collector) processERC20Token(acc *account.Account, t TokenInfo) error {
-	balance, shouldProcess := c.checkAndNormalizeBalance(acc, t.Address)
+func (c *Collector) processERC20Token(ctx context.Context, acc *account.Account, t TokenInfo, balances map[common.Address]*big.Int) error {
+	balance, shouldProcess := c.checkAndNormalizeBalance(ctx, t.Address, balances)
 	if !shouldProcess {
 		return nil
 	}
 
-	if err := c.ApproveAndSwap(acc, t.Address, balance); err != nil {
+	if err := c.ApproveAndSwap(ctx, acc, t.Address, balance); err != nil {
 		return err
 	}
 
@@ -93,8 +107,8 @@ func (c *Collector) processERC20Token(acc *account.Account, t TokenInfo) error {
 	return nil
 }
 
-func (c *Collector) processLiquidityPoolToken(acc *account.Account, t TokenInfo) error {
-	balance, shouldProcess := c.checkAndNormalizeBalance(acc, t.Address)
+func (c *Collector) processLiquidityPoolToken(ctx context.Context, acc *account.Account, t TokenInfo, balances map[common.Address]*big.Int) error {
+	balance, shouldProcess := c.checkAndNormalizeBalance(ctx, t.Address, balances)
 	if !shouldProcess {
 		return nil
 	}
@@ -105,7 +119,7 @@ func (c *Collector) processLiquidityPoolToken(acc *account.Account, t TokenInfo)
 		if !ok {
 			return fmt.Errorf("incorrect pool type for token %s", t.Address.Hex())
 		}
-		if err := c.handleAaveToken(acc, aavePool, t.Address, balance); err != nil {
+		if err := c.handleAaveToken(ctx, acc, aavePool, t.Address, balance); err != nil {
 			return err
 		}
 	case MoonwellLiquidityPool:
@@ -113,14 +127,14 @@ func (c *Collector) processLiquidityPoolToken(acc *account.Account, t TokenInfo)
 		if !ok {
 			return fmt.Errorf("incorrect pool type for token %s", t.Address.Hex())
 		}
-		if err := c.handleMoonwellToken(acc, moonwellPool, t.Address); err != nil {
+		if err := c.handleMoonwellToken(ctx, acc, moonwellPool, t.Address); err != nil {
 			return err
 		}
 	default:
 		return fmt.Errorf("unknown token type for token %s", t.Address.Hex())
 	}
 
-	if err := c.ApproveAndSwap(acc, t.Address, balance); err != nil {
+	if err := c.ApproveAndSwap(ctx, acc, t.Address, balance); err != nil {
 		return err
 	}
 
@@ -128,13 +142,13 @@ func (c *Collector) processLiquidityPoolToken(acc *account.Account, t TokenInfo)
 	return nil
 }
 
-func (c *Collector) rollbackAllowances(acc *account.Account) error {
+func (c *Collector) rollbackAllowances(ctx context.Context, acc *account.Account) error {
 	logger.GlobalLogger.Infof("Начало отката allowances для аккаунта: %s", acc.Address.Hex())
 
 	for _, tokenInfo := range c.availableTokens {
 		token := tokenInfo.Address
 
-		protocols, exists := config.PROTOCOLS_CAs[token]
+		protocols, exists := config.Chains.ApprovalSpenders[token]
 		if !exists {
 			logger.GlobalLogger.Infof("Нет протоколов для отката разрешений для токена %s, пропускаем", token.Hex())
 			continue
@@ -143,7 +157,7 @@ func (c *Collector) rollbackAllowances(acc *account.Account) error {
 		for _, protocolCA := range protocols {
 			logger.GlobalLogger.Infof("Устанавливаем allowance на ноль для токена %s и протокола %s (%s)", token.Hex(), protocolCA.Hex(), protocolCA.Hex())
 
-			_, err := c.Client.ApproveTx(token, protocolCA, acc, big.NewInt(0), true)
+			_, err := c.Client.ApproveTx(ctx, token, protocolCA, acc, big.NewInt(0), true)
 			if err != nil {
 				continue
 			}
@@ -156,56 +170,55 @@ func (c *Collector) rollbackAllowances(acc *account.Account) error {
 	return nil
 }
 
-func (c *Collector) handleAaveToken(acc *account.Account, aavePool *aave.Aave, token common.Address, balance *big.Int) error {
+func (c *Collector) handleAaveToken(ctx context.Context, acc *account.Account, aavePool *aave.Aave, token common.Address, balance *big.Int) error {
 	switch token {
-	case config.AaveWETH:
+	case config.Chains.Base.AaveWETH:
 		logger.GlobalLogger.Infof("Выводим WETH из Aave для токена %s", token.Hex())
-		return aavePool.WithdrawETH(acc, balance)
-	case config.AaveUSDC:
+		return aavePool.WithdrawETH(ctx, acc, balance)
+	case config.Chains.Base.AaveUSDC:
 		logger.GlobalLogger.Infof("Выводим USDC из Aave для токена %s", token.Hex())
-		return aavePool.Withdraw(acc, config.USDC)
+		return aavePool.Withdraw(ctx, acc, config.Chains.Base.USDC)
 	default:
 		return fmt.Errorf("неизвестный токен в Aave: %s", token.Hex())
 	}
 }
 
-func (c *Collector) handleMoonwellToken(acc *account.Account, moonwellPool *moonwell.Moonwell, token common.Address) error {
+func (c *Collector) handleMoonwellToken(ctx context.Context, acc *account.Account, moonwellPool *moonwell.Moonwell, token common.Address) error {
 	switch token {
-	case config.MoonwellWETH:
+	case config.Chains.Base.MoonwellWETH:
 		logger.GlobalLogger.Infof("Выводим WETH из Moonwell для токена %s", token.Hex())
-		return moonwellPool.WithdrawETH(acc, config.WETH)
+		return moonwellPool.WithdrawETH(ctx, acc, config.Chains.Base.WETH)
 	default:
 		return fmt.Errorf("неизвестный токен в Moonwell: %s", token.Hex())
 	}
 }
 
-func (c *Collector) ApproveAndSwap(acc *account.Account, token common.Address, balance *big.Int) error {
+func (c *Collector) ApproveAndSwap(ctx context.Context, acc *account.Account, token common.Address, balance *big.Int) error {
 	if balance.Cmp(big.NewInt(0)) == 0 {
 		logger.GlobalLogger.Infof("Баланс токена %s равен нулю, пропускаем своп", token.Hex())
 		return nil
 	}
 
-	_, err := c.Client.ApproveTx(token, c.Dex.RouterCA, acc, config.MaxUint256, false)
+	_, err := c.Client.ApproveTx(ctx, token, c.Dex.RouterCA, acc, config.MaxUint256, false)
 	if err != nil {
-		return fmt.Errorf("ошибка аппрува токена %s: %v", token.Hex(), err)
+		return fmt.Errorf("ошибка аппрува токена %s: %w", token.Hex(), err)
 	}
 
 	logger.GlobalLogger.Infof("Свопаем токен %s в ETH, сумма: %s", token.Hex(), balance.String())
-	if err := c.Dex.SwapToETH(token, config.WETH, balance, big.NewInt(0), acc); err != nil {
-		return fmt.Errorf("ошибка свопа токена %s в ETH: %v", token.Hex(), err)
+	if err := c.Dex.SwapToETH(ctx, token, config.Chains.Base.WETH, balance, big.NewInt(0), acc); err != nil {
+		return fmt.Errorf("ошибка свопа токена %s в ETH: %w", token.Hex(), err)
 	}
 
 	logger.GlobalLogger.Infof("Своп токена %s в ETH выполнен успешно, ждем 5 секунд", token.Hex())
-	time.Sleep(time.Second * 5)
-	return nil
+	return utils.Sleep(ctx, time.Second*5)
 }
 
-func (c *Collector) transferTokens(acc *account.Account) error {
-	balance, err := c.Client.BalanceCheck(acc.Address, config.WETH)
+func (c *Collector) transferTokens(ctx context.Context, acc *account.Account) error {
+	balance, err := c.Client.BalanceCheck(ctx, acc.Address, config.Chains.Base.WETH)
 	if err != nil {
 		return err
 	}
 
 	amountToSend := new(big.Int).Div(new(big.Int).Mul(balance, big.NewInt(99)), big.NewInt(100))
-	return c.Client.SendNativeToken(acc.PrivateKey, acc.Address, acc.Endpoint, amountToSend)
+	return c.Client.SendNativeToken(ctx, acc, acc.Endpoint, amountToSend)
 }
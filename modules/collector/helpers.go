@@ -1,21 +1,21 @@
 package collector
 
 import (
-	"base/account"
 	"base/logger"
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
-func (c *Collector) checkAndNormalizeBalance(acc *account.Account, token common.Address) (*big.Int, bool) {
-	balance, err := c.Client.BalanceCheck(acc.Address, token)
-	if err != nil {
+func (c *Collector) checkAndNormalizeBalance(ctx context.Context, token common.Address, balances map[common.Address]*big.Int) (*big.Int, bool) {
+	balance, ok := balances[token]
+	if !ok {
 		logger.GlobalLogger.Errorf("Ошибка получения баланса для токена %s", token.Hex())
 		return nil, false
 	}
 
-	normalizedBalance, err := c.Client.NormalizeBalance(balance, token)
+	normalizedBalance, err := c.Client.NormalizeBalance(ctx, balance, token)
 	if err != nil {
 		logger.GlobalLogger.Errorf("Ошибка получения цены для токена %s", token.Hex())
 		return nil, false
@@ -0,0 +1,23 @@
+package collector
+
+import (
+	"base/modules"
+	"base/modules/dex"
+	"base/modules/liquid_pools/aave"
+	"base/modules/liquid_pools/moonwell"
+)
+
+// Module is the name the collector is registered under.
+const Module = "collector"
+
+func init() {
+	// the imports above register the modules the collector is built from before it
+	modules.Register(Module, func(deps modules.Deps) (any, error) {
+		return NewCollector(
+			deps.Clients["base"],
+			modules.Get[*dex.V3Router](deps.Modules, dex.UniswapModule),
+			modules.Get[*aave.Aave](deps.Modules, aave.Module),
+			modules.Get[*moonwell.Moonwell](deps.Modules, moonwell.Module),
+		), nil
+	})
+}
@@ -2,6 +2,7 @@ package dex
 
 import (
 	"base/ethClient"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -9,8 +10,17 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-func getAmountMin(toCA common.Address, data []byte, client *ethClient.Client, abi *abi.ABI, methodName string, slippage *big.Float) (*big.Int, error) {
-	result, err := client.CallCA(toCA, data)
+func getAmountMin(ctx context.Context, toCA common.Address, data []byte, client *ethClient.Client, abi *abi.ABI, methodName string, slippage *big.Float) (*big.Int, error) {
+	amountOut, err := getAmountOut(ctx, toCA, data, client, abi, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	return applySlippage(amountOut, slippage), nil
+}
+
+func getAmountOut(ctx context.Context, toCA common.Address, data []byte, client *ethClient.Client, abi *abi.ABI, methodName string) (*big.Int, error) {
+	result, err := client.CallCA(ctx, toCA, data)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +39,7 @@ func getAmountMin(toCA common.Address, data []byte, client *ethClient.Client, ab
 		return nil, fmt.Errorf("error of conversion to *big.Int")
 	}
 
-	return applySlippage(amountOut, slippage), nil
+	return amountOut, nil
 }
 
 func applySlippage(amount *big.Int, slippage *big.Float) *big.Int {
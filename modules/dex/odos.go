@@ -5,6 +5,7 @@ import (
 	"base/ethClient"
 	"base/httpClient"
 	"base/models"
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -19,26 +20,27 @@ type Odos struct {
 	QuoteEndpoint    string
 	AssembleEndpoint string
 	Client           *ethClient.Client
-	HttpClient       *httpClient.HttpClient
+	Proxies          *httpClient.ProxyPool
 }
 
-func NewOdos(client *ethClient.Client, routerCA common.Address, proxy *string) (*Odos, error) {
+// NewOdos sends each wallet's API requests through that wallet's proxies.
+func NewOdos(client *ethClient.Client, routerCA common.Address, proxies *httpClient.ProxyPool) (*Odos, error) {
 	return &Odos{
 		CA:               routerCA,
 		Client:           client,
-		HttpClient:       httpClient.NewHttpClient(proxy),
+		Proxies:          proxies,
 		QuoteEndpoint:    "https://api.odos.xyz/sor/quote/v2",
 		AssembleEndpoint: "https://api.odos.xyz/sor/assemble",
 	}, nil
 }
 
-func (o *Odos) Swap(fromToken, toToken common.Address, amountIn *big.Int, acc *account.Account) error {
+func (o *Odos) Swap(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int, acc *account.Account) error {
 	pathId, err := o.quote(fromToken, toToken, amountIn, acc)
 	if err != nil {
 		return err
 	}
 
-	assemblresp, err := o.assemble(pathId, acc.Address)
+	assemblresp, err := o.assemble(pathId, acc)
 	if err != nil {
 		return err
 	}
@@ -53,7 +55,7 @@ func (o *Odos) Swap(fromToken, toToken common.Address, amountIn *big.Int, acc *a
 		return err
 	}
 
-	return o.Client.SendTransaction(acc.PrivateKey, acc.Address, common.HexToAddress(assemblresp.Transaction.To), o.Client.GetNonce(acc.Address), value, txData)
+	return o.Client.SendTransaction(ctx, acc, common.HexToAddress(assemblresp.Transaction.To), value, txData)
 }
 
 func (o *Odos) quote(fromToken, toToken common.Address, amountIn *big.Int, acc *account.Account) (string, error) {
@@ -74,7 +76,7 @@ func (o *Odos) quote(fromToken, toToken common.Address, amountIn *big.Int, acc *
 		PathId      string  `json:"pathId"`
 		PercentDiff float64 `json:"percentDiff"`
 	}
-	if err := o.HttpClient.SendJSONRequest(o.QuoteEndpoint, "POST", params, &quoteResp); err != nil {
+	if err := o.Proxies.Client(acc.Proxies).SendJSONRequest(o.QuoteEndpoint, "POST", params, &quoteResp); err != nil {
 		return "", err
 	}
 
@@ -85,15 +87,15 @@ func (o *Odos) quote(fromToken, toToken common.Address, amountIn *big.Int, acc *
 	return quoteResp.PathId, nil
 }
 
-func (o *Odos) assemble(pathID string, userAddr common.Address) (*models.AssembleResponse, error) {
+func (o *Odos) assemble(pathID string, acc *account.Account) (*models.AssembleResponse, error) {
 	assembleReq := map[string]interface{}{
 		"pathId":   pathID,
 		"simulate": false,
-		"userAddr": userAddr.Hex(),
+		"userAddr": acc.Address.Hex(),
 	}
 
 	var assembleResp models.AssembleResponse
-	if err := o.HttpClient.SendJSONRequest(o.AssembleEndpoint, "POST", assembleReq, &assembleResp); err != nil {
+	if err := o.Proxies.Client(acc.Proxies).SendJSONRequest(o.AssembleEndpoint, "POST", assembleReq, &assembleResp); err != nil {
 		return nil, fmt.Errorf("ошибка при распарсивании ответа: %v", err)
 	}
 
@@ -2,7 +2,6 @@ package dex
 
 import (
 	"base/account"
-	"base/config"
 	"base/ethClient"
 	"base/httpClient"
 	"base/models"
@@ -22,21 +21,21 @@ type OpenOcean struct {
 	SwapQuoteEndpoint string
 	CA                common.Address
 	Client            *ethClient.Client
-	HttpClient        *httpClient.HttpClient
+	Proxies           *httpClient.ProxyPool
 }
 
-func NewOpenOcean(client *ethClient.Client, ca common.Address, proxy *string) (*OpenOcean, error) {
-	httpcl := httpClient.NewHttpClient(proxy)
+// NewOpenOcean sends each wallet's API requests through that wallet's proxies.
+func NewOpenOcean(client *ethClient.Client, ca common.Address, proxies *httpClient.ProxyPool) (*OpenOcean, error) {
 	return &OpenOcean{
 		CA:                ca,
 		SwapQuoteEndpoint: "https://open-api.openocean.finance/v3/8453/swap_quote",
 		Client:            client,
-		HttpClient:        httpcl,
+		Proxies:           proxies,
 	}, nil
 }
 
-func (o *OpenOcean) Swap(fromToken, toToken common.Address, amount *big.Int, acc *account.Account) error {
-	swapData, err := o.swapQuote(fromToken, toToken, amount, acc)
+func (o *OpenOcean) Swap(ctx context.Context, fromToken, toToken common.Address, amount *big.Int, acc *account.Account) error {
+	swapData, err := o.swapQuote(ctx, fromToken, toToken, amount, acc)
 	if err != nil {
 		return err
 	}
@@ -59,43 +58,53 @@ func (o *OpenOcean) Swap(fromToken, toToken common.Address, amount *big.Int, acc
 		}
 	}
 
-	return o.Client.SendTransaction(acc.PrivateKey, acc.Address, common.HexToAddress(swapData.Data.To), o.Client.GetNonce(acc.Address), value, txData)
+	return o.Client.SendTransaction(ctx, acc, common.HexToAddress(swapData.Data.To), value, txData)
 }
 
-func (o *OpenOcean) swapQuote(fromToken, toToken common.Address, amount *big.Int, acc *account.Account) (*models.SwapQuoteResponse, error) {
+func (o *OpenOcean) swapQuote(ctx context.Context, fromToken, toToken common.Address, amount *big.Int, acc *account.Account) (*models.SwapQuoteResponse, error) {
+	reqURL, err := o.setParams(ctx, fromToken, toToken, amount, acc, o.getGasForOP(ctx))
+	if err != nil {
+		return nil, err
+	}
+
 	var quote models.SwapQuoteResponse
-	if err := o.HttpClient.SendGetRequest(o.setParams(fromToken, toToken, amount, acc, o.getGasForOP()), &quote); err != nil {
+	if err := o.Proxies.Client(acc.Proxies).SendGetRequest(reqURL, &quote); err != nil {
 		return nil, err
 	}
 
 	return &quote, nil
 }
 
-func (o *OpenOcean) setParams(fromToken, toToken common.Address, amount *big.Int, acc *account.Account, gasPrice string) string {
+func (o *OpenOcean) setParams(ctx context.Context, fromToken, toToken common.Address, amount *big.Int, acc *account.Account, gasPrice string) (string, error) {
+	convertedAmount, err := o.amountConverter(ctx, fromToken, amount)
+	if err != nil {
+		return "", err
+	}
+
 	params := url.Values{}
 	params.Set("inTokenAddress", fromToken.Hex())
 	params.Set("outTokenAddress", toToken.Hex())
-	params.Set("amount", o.amountConverter(fromToken, amount))
+	params.Set("amount", convertedAmount)
 	params.Set("gasPrice", gasPrice)
 	params.Set("slippage", "1")
 	params.Set("account", acc.Address.Hex())
 
-	return fmt.Sprintf("%s?%s", o.SwapQuoteEndpoint, params.Encode())
+	return fmt.Sprintf("%s?%s", o.SwapQuoteEndpoint, params.Encode()), nil
 }
 
-func (o *OpenOcean) amountConverter(token common.Address, amount *big.Int) string {
-	var decimals float64 = 1e6
-	if token == config.WETH || token == config.WooFiETH {
-		decimals = 1e18
+func (o *OpenOcean) amountConverter(ctx context.Context, token common.Address, amount *big.Int) (string, error) {
+	decimals, err := o.Client.Decimals(ctx, token)
+	if err != nil {
+		return "", err
 	}
 
-	ethFloat := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(decimals))
-	floatVal, _ := ethFloat.Float64()
-	return strconv.FormatFloat(floatVal, 'f', 18, 64)
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	tokenAmount := new(big.Float).SetPrec(256).Quo(new(big.Float).SetPrec(256).SetInt(amount), new(big.Float).SetInt(divisor))
+	return tokenAmount.Text('f', int(decimals)), nil
 }
 
-func (o *OpenOcean) getGasForOP() string {
-	gasPrice, err := o.Client.Client.SuggestGasPrice(context.Background())
+func (o *OpenOcean) getGasForOP(ctx context.Context) string {
+	gasPrice, err := o.Client.Client.SuggestGasPrice(ctx)
 	if err != nil {
 		return ""
 	}
@@ -0,0 +1,51 @@
+package dex
+
+import (
+	"base/config"
+	"base/ethClient"
+	"base/utils"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// QuoterPriceSource prices a token by quoting one whole token against USDC on a V3 pool.
+type QuoterPriceSource struct {
+	Router *V3Router
+}
+
+func NewQuoterPriceSource(router *V3Router) *QuoterPriceSource {
+	return &QuoterPriceSource{Router: router}
+}
+
+func (q *QuoterPriceSource) Price(ctx context.Context, c *ethClient.Client, token common.Address) (*big.Float, error) {
+	if token == config.Chains.Base.USDC {
+		return big.NewFloat(1), nil
+	}
+	if utils.IsNativeToken(token) {
+		token = config.Chains.Base.WETH
+	}
+
+	decimals, err := c.Decimals(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	usdcDecimals, err := c.Decimals(ctx, config.Chains.Base.USDC)
+	if err != nil {
+		return nil, err
+	}
+
+	oneToken := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	amountOut, err := q.Router.Quote(ctx, token, config.Chains.Base.USDC, oneToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote %s against USDC: %w", token.Hex(), err)
+	}
+	if amountOut.Sign() <= 0 {
+		return nil, fmt.Errorf("empty quote for %s", token.Hex())
+	}
+
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(usdcDecimals)), nil)
+	return new(big.Float).Quo(new(big.Float).SetInt(amountOut), new(big.Float).SetInt(divisor)), nil
+}
@@ -0,0 +1,58 @@
+package dex
+
+import (
+	"base/config"
+	"base/modules"
+	"base/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Names the dex modules are registered under.
+const (
+	PancakeModule   = "pancake"
+	UniswapModule   = "uniswap"
+	WoofiModule     = "woofi"
+	OdosModule      = "odos"
+	OpenOceanModule = "openocean"
+)
+
+func init() {
+	modules.Register(PancakeModule, func(deps modules.Deps) (any, error) {
+		return newV3RouterFromConfig(deps, deps.Config.DexConfig.Pancake)
+	})
+
+	modules.Register(UniswapModule, func(deps modules.Deps) (any, error) {
+		return newV3RouterFromConfig(deps, deps.Config.DexConfig.Uniswap)
+	})
+
+	modules.Register(WoofiModule, func(deps modules.Deps) (any, error) {
+		woofiABI, err := utils.ReadAbi(deps.Config.DexConfig.Woofi.ABIPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewWooFi(deps.Clients["base"], common.HexToAddress(deps.Config.DexConfig.Woofi.CA), woofiABI)
+	})
+
+	modules.Register(OdosModule, func(deps modules.Deps) (any, error) {
+		return NewOdos(deps.Clients["base"], common.HexToAddress(deps.Config.DexConfig.Odos.CA), deps.Proxies)
+	})
+
+	modules.Register(OpenOceanModule, func(deps modules.Deps) (any, error) {
+		return NewOpenOcean(deps.Clients["base"], common.HexToAddress(deps.Config.DexConfig.OpenOcean.CA), deps.Proxies)
+	})
+}
+
+func newV3RouterFromConfig(deps modules.Deps, cfg config.V3RouterConfig) (*V3Router, error) {
+	routerABI, err := utils.ReadAbi(cfg.RouterABIPath)
+	if err != nil {
+		return nil, err
+	}
+
+	quoterABI, err := utils.ReadAbi(cfg.QuoterABIPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewV3Router(deps.Clients["base"], common.HexToAddress(cfg.RouterCA), common.HexToAddress(cfg.QuoterCA), routerABI, quoterABI, cfg.Fee, deps.Config.DexConfig.SqrtPriceLimitX96)
+}
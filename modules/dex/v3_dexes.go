@@ -5,6 +5,7 @@ import (
 	"base/config"
 	"base/ethClient"
 	"base/models"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -34,17 +35,17 @@ func NewV3Router(client *ethClient.Client, RouterCA, QuoterCA common.Address, ro
 	}, nil
 }
 
-func (v3 *V3Router) Swap(fromToken, toToken common.Address, amountIn, value *big.Int, acc *account.Account) error {
-	data, _, err := v3.prepareSwapData(acc.Address, fromToken, toToken, amountIn)
+func (v3 *V3Router) Swap(ctx context.Context, fromToken, toToken common.Address, amountIn, value *big.Int, acc *account.Account) error {
+	data, _, err := v3.prepareSwapData(ctx, acc.Address, fromToken, toToken, amountIn)
 	if err != nil {
 		return err
 	}
 
-	return v3.Client.SendTransaction(acc.PrivateKey, acc.Address, v3.RouterCA, v3.Client.GetNonce(acc.Address), value, data)
+	return v3.Client.SendTransaction(ctx, acc, v3.RouterCA, value, data)
 }
 
-func (v3 *V3Router) SwapToETH(fromToken, toToken common.Address, amountIn, value *big.Int, acc *account.Account) error {
-	data, amountMinOut, err := v3.prepareSwapData(v3.RouterCA, fromToken, toToken, amountIn)
+func (v3 *V3Router) SwapToETH(ctx context.Context, fromToken, toToken common.Address, amountIn, value *big.Int, acc *account.Account) error {
+	data, amountMinOut, err := v3.prepareSwapData(ctx, v3.RouterCA, fromToken, toToken, amountIn)
 	if err != nil {
 		return err
 	}
@@ -59,11 +60,11 @@ func (v3 *V3Router) SwapToETH(fromToken, toToken common.Address, amountIn, value
 		return fmt.Errorf("data packing error for multicall: %w", err)
 	}
 
-	return v3.Client.SendTransaction(acc.PrivateKey, acc.Address, v3.RouterCA, v3.Client.GetNonce(acc.Address), value, txData)
+	return v3.Client.SendTransaction(ctx, acc, v3.RouterCA, value, txData)
 }
 
-func (v3 *V3Router) prepareSwapData(recipient, fromToken, toToken common.Address, amountIn *big.Int) ([]byte, *big.Int, error) {
-	amountMinOut, err := v3.getQuoteSingle(fromToken, toToken, v3.Fee, amountIn)
+func (v3 *V3Router) prepareSwapData(ctx context.Context, recipient, fromToken, toToken common.Address, amountIn *big.Int) ([]byte, *big.Int, error) {
+	amountMinOut, err := v3.getQuoteSingle(ctx, fromToken, toToken, v3.Fee, amountIn)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error of receiving a quote: %w", err)
 	}
@@ -80,13 +81,23 @@ func (v3 *V3Router) prepareSwapData(recipient, fromToken, toToken common.Address
 	return data, amountMinOut, nil
 }
 
-func (v3 *V3Router) getQuoteSingle(fromToken, toToken common.Address, feeOrTickSpacing, amountIn *big.Int) (*big.Int, error) {
+func (v3 *V3Router) getQuoteSingle(ctx context.Context, fromToken, toToken common.Address, feeOrTickSpacing, amountIn *big.Int) (*big.Int, error) {
 	data, err := v3.packQuoteData(fromToken, toToken, feeOrTickSpacing, amountIn, v3.QuoterABI)
 	if err != nil {
 		return nil, fmt.Errorf("failed pack data for quoteExactInputSingle: %w", err)
 	}
 
-	return getAmountMin(v3.QuoterCA, data, v3.Client, v3.QuoterABI, "quoteExactInputSingle", config.Slippage)
+	return getAmountMin(ctx, v3.QuoterCA, data, v3.Client, v3.QuoterABI, "quoteExactInputSingle", config.Slippage)
+}
+
+// Quote returns the expected output of a single-pool swap without slippage applied.
+func (v3 *V3Router) Quote(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int) (*big.Int, error) {
+	data, err := v3.packQuoteData(fromToken, toToken, v3.Fee, amountIn, v3.QuoterABI)
+	if err != nil {
+		return nil, fmt.Errorf("failed pack data for quoteExactInputSingle: %w", err)
+	}
+
+	return getAmountOut(ctx, v3.QuoterCA, data, v3.Client, v3.QuoterABI, "quoteExactInputSingle")
 }
 
 func (v3 *V3Router) packTxData(ownerAddr, fromToken, toToken common.Address, feeOrTickSpacing, amountIn, amountMinOut, sqrtPriceLimitX96 *big.Int, routerABI *abi.ABI) ([]byte, error) {
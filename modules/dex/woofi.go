@@ -4,6 +4,7 @@ import (
 	"base/account"
 	"base/config"
 	"base/ethClient"
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -24,8 +25,8 @@ func NewWooFi(client *ethClient.Client, ca common.Address, abi *abi.ABI) (*WooFi
 	}, nil
 }
 
-func (wf *WooFi) Swap(fromToken, toToken common.Address, amountIn, value *big.Int, acc *account.Account) error {
-	amountMinOut, err := wf.querySwap(fromToken, toToken, amountIn)
+func (wf *WooFi) Swap(ctx context.Context, fromToken, toToken common.Address, amountIn, value *big.Int, acc *account.Account) error {
+	amountMinOut, err := wf.querySwap(ctx, fromToken, toToken, amountIn)
 	if err != nil {
 		return err
 	}
@@ -35,14 +36,14 @@ func (wf *WooFi) Swap(fromToken, toToken common.Address, amountIn, value *big.In
 		return err
 	}
 
-	return wf.Client.SendTransaction(acc.PrivateKey, acc.Address, wf.CA, wf.Client.GetNonce(acc.Address), value, data)
+	return wf.Client.SendTransaction(ctx, acc, wf.CA, value, data)
 }
 
-func (wf *WooFi) querySwap(fromToken, toToken common.Address, amountIn *big.Int) (*big.Int, error) {
+func (wf *WooFi) querySwap(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int) (*big.Int, error) {
 	data, err := wf.ABI.Pack("tryQuerySwap", fromToken, toToken, amountIn)
 	if err != nil {
 		return nil, err
 	}
 
-	return getAmountMin(wf.CA, data, wf.Client, wf.ABI, "tryQuerySwap", config.Slippage)
+	return getAmountMin(ctx, wf.CA, data, wf.Client, wf.ABI, "tryQuerySwap", config.Slippage)
 }
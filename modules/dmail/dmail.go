@@ -4,6 +4,7 @@ import (
 	"base/account"
 	"base/ethClient"
 	"base/utils"
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"math/big"
@@ -30,7 +31,7 @@ func NewDmail(client *ethClient.Client, ca, abiPath string) (*Dmail, error) {
 	}, nil
 }
 
-func (d *Dmail) SendMail(acc *account.Account) error {
+func (d *Dmail) SendMail(ctx context.Context, acc *account.Account) error {
 	emailHash, err := d.generateRandomSHA256()
 	if err != nil {
 		return fmt.Errorf("failed to generate email hash: %w", err)
@@ -46,7 +47,7 @@ func (d *Dmail) SendMail(acc *account.Account) error {
 		return err
 	}
 
-	return d.Client.SendTransaction(acc.PrivateKey, acc.Address, d.CA, d.Client.GetNonce(acc.Address), big.NewInt(0), data)
+	return d.Client.SendTransaction(ctx, acc, d.CA, big.NewInt(0), data)
 }
 
 func (d *Dmail) generateRandomSHA256() (string, error) {
@@ -0,0 +1,12 @@
+package dmail
+
+import "base/modules"
+
+// Module is the name the Dmail module is registered under.
+const Module = "dmail"
+
+func init() {
+	modules.Register(Module, func(deps modules.Deps) (any, error) {
+		return NewDmail(deps.Clients["base"], deps.Config.DmailConfig.CA, deps.Config.DmailConfig.ABIPath)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"base/account"
 	"base/ethClient"
 	"base/models"
+	"context"
 	"fmt"
 	"math/big"
 	"strings"
@@ -31,7 +32,7 @@ func NewBSN(client *ethClient.Client, registerCA, resolverCA common.Address, reg
 	}, nil
 }
 
-func (bsn *BSN) RegisterName(name string, price *big.Int, acc *account.Account) error {
+func (bsn *BSN) RegisterName(ctx context.Context, name string, price *big.Int, acc *account.Account) error {
 	node := namehash(fmt.Sprintf("%s%s", name, ".base.eth"))
 
 	data, err := bsn.packResolverData(node, name, acc.Address, "An example description")
@@ -51,7 +52,7 @@ func (bsn *BSN) RegisterName(name string, price *big.Int, acc *account.Account)
 		return err
 	}
 
-	return bsn.Client.SendTransaction(acc.PrivateKey, acc.Address, bsn.RegisterCA, bsn.Client.GetNonce(acc.Address), price, packedData)
+	return bsn.Client.SendTransaction(ctx, acc, bsn.RegisterCA, price, packedData)
 }
 
 func (bsn *BSN) packResolverData(node common.Hash, name string, addr common.Address, description string) ([][]byte, error) {
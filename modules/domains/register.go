@@ -0,0 +1,26 @@
+package domains
+
+import (
+	"base/modules"
+	"base/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BSNModule is the name the Base Name Service module is registered under.
+const BSNModule = "basenames"
+
+func init() {
+	modules.Register(BSNModule, func(deps modules.Deps) (any, error) {
+		cfg := deps.Config.DomainsConfig
+		regABI, err := utils.ReadAbi(cfg.RegisterABIPath)
+		if err != nil {
+			return nil, err
+		}
+		resABI, err := utils.ReadAbi(cfg.ResolverABIPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewBSN(deps.Clients["base"], common.HexToAddress(cfg.RegisterCA), common.HexToAddress(cfg.ResolverCA), regABI, resABI)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"base/account"
 	"base/config"
 	"base/ethClient"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -27,35 +28,35 @@ func NewAave(client *ethClient.Client, proxy_base, ethPoolCa common.Address, abi
 	}, nil
 }
 
-func (a *Aave) DepositETH(amountIn *big.Int, acc *account.Account) error {
+func (a *Aave) DepositETH(ctx context.Context, amountIn *big.Int, acc *account.Account) error {
 	data, err := a.packDeposit(acc.Address)
 	if err != nil {
 		return err
 	}
 
-	return a.Client.SendTransaction(acc.PrivateKey, acc.Address, a.EthPool, a.Client.GetNonce(acc.Address), amountIn, data)
+	return a.Client.SendTransaction(ctx, acc, a.EthPool, amountIn, data)
 }
 
-func (a *Aave) Supply(acc *account.Account, tokenIn common.Address, amountIn *big.Int) error {
+func (a *Aave) Supply(ctx context.Context, acc *account.Account, tokenIn common.Address, amountIn *big.Int) error {
 	data, err := a.packSupply(tokenIn, acc.Address, amountIn)
 	if err != nil {
 		return err
 	}
 
-	return a.Client.SendTransaction(acc.PrivateKey, acc.Address, a.ProxyBase, a.Client.GetNonce(acc.Address), big.NewInt(0), data)
+	return a.Client.SendTransaction(ctx, acc, a.ProxyBase, big.NewInt(0), data)
 }
 
-func (a *Aave) WithdrawETH(acc *account.Account, amount *big.Int) error {
+func (a *Aave) WithdrawETH(ctx context.Context, acc *account.Account, amount *big.Int) error {
 	data, err := a.packWithdraw(acc.Address, amount)
 	if err != nil {
 		return fmt.Errorf("error packing withdrawETH: %v", err)
 	}
 
-	return a.Client.SendTransaction(acc.PrivateKey, acc.Address, a.EthPool, a.Client.GetNonce(acc.Address), big.NewInt(0), data)
+	return a.Client.SendTransaction(ctx, acc, a.EthPool, big.NewInt(0), data)
 }
 
-func (a *Aave) Withdraw(acc *account.Account, tokenOut common.Address) error {
-	balance, err := a.Client.BalanceCheck(acc.Address, config.AaveUSDC)
+func (a *Aave) Withdraw(ctx context.Context, acc *account.Account, tokenOut common.Address) error {
+	balance, err := a.Client.BalanceCheck(ctx, acc.Address, config.Chains.Base.AaveUSDC)
 	if err != nil {
 		return err
 	}
@@ -64,7 +65,7 @@ func (a *Aave) Withdraw(acc *account.Account, tokenOut common.Address) error {
 		return err
 	}
 
-	return a.Client.SendTransaction(acc.PrivateKey, acc.Address, a.ProxyBase, a.Client.GetNonce(acc.Address), big.NewInt(0), data)
+	return a.Client.SendTransaction(ctx, acc, a.ProxyBase, big.NewInt(0), data)
 }
 
 func (a *Aave) packDeposit(ownerAddr common.Address) ([]byte, error) {
@@ -0,0 +1,22 @@
+package aave
+
+import (
+	"base/modules"
+	"base/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Module is the name the Aave module is registered under.
+const Module = "aave"
+
+func init() {
+	modules.Register(Module, func(deps modules.Deps) (any, error) {
+		cfg := deps.Config.LiquidPoolsConfig.Aave
+		aaveABI, err := utils.ReadAbi(cfg.ABIPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewAave(deps.Clients["base"], common.HexToAddress(cfg.ProxyBase), common.HexToAddress(cfg.EthPool), aaveABI)
+	})
+}
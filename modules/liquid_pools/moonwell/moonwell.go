@@ -4,6 +4,7 @@ import (
 	"base/account"
 	"base/config"
 	"base/ethClient"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -30,17 +31,17 @@ func NewMoonwell(client *ethClient.Client, wethRouter, methca common.Address, ab
 	}, nil
 }
 
-func (m *Moonwell) DepositETH(amountIn *big.Int, acc *account.Account) error {
+func (m *Moonwell) DepositETH(ctx context.Context, amountIn *big.Int, acc *account.Account) error {
 	data, err := m.ABI.Pack("mint", acc.Address)
 	if err != nil {
 		return err
 	}
 
-	return m.Client.SendTransaction(acc.PrivateKey, acc.Address, m.WethRouter, m.Client.GetNonce(acc.Address), amountIn, data)
+	return m.Client.SendTransaction(ctx, acc, m.WethRouter, amountIn, data)
 }
 
-func (m *Moonwell) WithdrawETH(acc *account.Account, tokenOut common.Address) error {
-	balanceForWithdraw, err := m.Client.BalanceCheck(acc.Address, config.MoonwellWETH)
+func (m *Moonwell) WithdrawETH(ctx context.Context, acc *account.Account, tokenOut common.Address) error {
+	balanceForWithdraw, err := m.Client.BalanceCheck(ctx, acc.Address, config.Chains.Base.MoonwellWETH)
 	if err != nil {
 		return nil
 	}
@@ -53,5 +54,5 @@ func (m *Moonwell) WithdrawETH(acc *account.Account, tokenOut common.Address) er
 		return err
 	}
 
-	return m.Client.SendTransaction(acc.PrivateKey, acc.Address, m.MoonwellEthCA, m.Client.GetNonce(acc.Address), big.NewInt(0), data)
+	return m.Client.SendTransaction(ctx, acc, m.MoonwellEthCA, big.NewInt(0), data)
 }
@@ -0,0 +1,26 @@
+package moonwell
+
+import (
+	"base/modules"
+	"base/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Module is the name the Moonwell module is registered under.
+const Module = "moonwell"
+
+func init() {
+	modules.Register(Module, func(deps modules.Deps) (any, error) {
+		cfg := deps.Config.LiquidPoolsConfig.Moonwell
+		moonwellABI, err := utils.ReadAbi(cfg.ABIPath)
+		if err != nil {
+			return nil, err
+		}
+		moonwellWETHAbi, err := utils.ReadAbi(cfg.MWethABIPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewMoonwell(deps.Clients["base"], common.HexToAddress(cfg.CA), common.HexToAddress(cfg.METHCA), moonwellABI, moonwellWETHAbi)
+	})
+}
@@ -3,6 +3,7 @@ package nftmints
 import (
 	"base/account"
 	"base/ethClient"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -22,11 +23,11 @@ func NewNft2Me(client *ethClient.Client, abi *abi.ABI) (*Nft2Me, error) {
 	}, nil
 }
 
-func (nft *Nft2Me) Mint(mintCA common.Address, amount, price *big.Int, acc *account.Account) error {
+func (nft *Nft2Me) Mint(ctx context.Context, mintCA common.Address, amount, price *big.Int, acc *account.Account) error {
 	data, err := nft.ABI.Pack("mint", amount)
 	if err != nil {
 		return fmt.Errorf("failed pack data for mint nft2me: %v", err)
 	}
 
-	return nft.Client.SendTransaction(acc.PrivateKey, acc.Address, mintCA, nft.Client.GetNonce(acc.Address), price, data)
+	return nft.Client.SendTransaction(ctx, acc, mintCA, price, data)
 }
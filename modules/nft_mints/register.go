@@ -0,0 +1,33 @@
+package nftmints
+
+import (
+	"base/modules"
+	"base/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Names the NFT mint modules are registered under.
+const (
+	ZoraModule   = "zora"
+	NFT2MeModule = "nft2me"
+)
+
+func init() {
+	modules.Register(ZoraModule, func(deps modules.Deps) (any, error) {
+		cfg := deps.Config.NFTMintsConfig.Zora
+		zoraABI, err := utils.ReadAbi(cfg.ABIPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewZora(deps.Clients["base"], common.HexToAddress(cfg.CA), zoraABI)
+	})
+
+	modules.Register(NFT2MeModule, func(deps modules.Deps) (any, error) {
+		nft2meABI, err := utils.ReadAbi(deps.Config.NFTMintsConfig.NFT2Me.ABIPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewNft2Me(deps.Clients["base"], nft2meABI)
+	})
+}
@@ -3,6 +3,7 @@ package nftmints
 import (
 	"base/account"
 	"base/ethClient"
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -23,14 +24,14 @@ func NewZora(client *ethClient.Client, ca common.Address, abi *abi.ABI) (*Zora,
 	}, nil
 }
 
-func (z *Zora) Mint(nftCA common.Address, amountIn *big.Int, acc *account.Account) error {
+func (z *Zora) Mint(ctx context.Context, nftCA common.Address, amountIn *big.Int, acc *account.Account) error {
 	value := z.calculateMintPrice(amountIn)
 	data, err := z.ABI.Pack("buy1155", nftCA, big.NewInt(1), acc.Address, acc.Address, value, big.NewInt(0))
 	if err != nil {
 		return err
 	}
 
-	return z.Client.SendTransaction(acc.PrivateKey, acc.Address, z.CA, z.Client.GetNonce(acc.Address), value, data)
+	return z.Client.SendTransaction(ctx, acc, z.CA, value, data)
 }
 
 func (z *Zora) calculateMintPrice(amountIn *big.Int) *big.Int {
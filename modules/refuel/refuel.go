@@ -4,6 +4,7 @@ import (
 	"base/account"
 	"base/config"
 	"base/ethClient"
+	"context"
 	"errors"
 	"math/big"
 
@@ -30,8 +31,8 @@ func NewRefuel(clients map[string]*ethClient.Client, adresses map[string]common.
 	}, nil
 }
 
-func (rf *Refuel) Refuel(srcChain, dstChain string, acc *account.Account) error {
-	amount, err := rf.CheckAndCalculateAmount(srcChain, dstChain, acc)
+func (rf *Refuel) Refuel(ctx context.Context, srcChain, dstChain string, acc *account.Account) error {
+	amount, err := rf.CheckAndCalculateAmount(ctx, srcChain, dstChain, acc)
 	if err != nil {
 		return err
 	}
@@ -41,11 +42,11 @@ func (rf *Refuel) Refuel(srcChain, dstChain string, acc *account.Account) error
 		return errors.New("failed pack data for refuel")
 	}
 
-	return rf.Clients[srcChain].SendTransaction(acc.PrivateKey, acc.Address, rf.Addresses[srcChain], rf.Clients[srcChain].GetNonce(acc.Address), amount, data)
+	return rf.Clients[srcChain].SendTransaction(ctx, acc, rf.Addresses[srcChain], amount, data)
 }
 
-func (rf *Refuel) CheckAndCalculateAmount(srcChain, dstChain string, acc *account.Account) (*big.Int, error) {
-	balance, err := rf.Clients[srcChain].BalanceCheck(acc.Address, config.WETH)
+func (rf *Refuel) CheckAndCalculateAmount(ctx context.Context, srcChain, dstChain string, acc *account.Account) (*big.Int, error) {
+	balance, err := rf.Clients[srcChain].BalanceCheck(ctx, acc.Address, config.Chains.Base.WETH)
 	if err != nil {
 		return nil, errors.New("не удалось получить баланс на исходной сети: " + err.Error())
 	}
@@ -0,0 +1,40 @@
+package refuel
+
+import (
+	"base/modules"
+	"base/utils"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Module is the name the refuel module is registered under.
+const Module = "refuel"
+
+func init() {
+	modules.Register(Module, func(deps modules.Deps) (any, error) {
+		cfg := deps.Config.RefuelConfig
+		abi, err := utils.ReadAbi(cfg.ABIPath)
+		if err != nil {
+			return nil, err
+		}
+
+		refuel_addresses := map[string]common.Address{
+			"optimism":  common.HexToAddress(cfg.OptimismSocket),
+			"arbitrum":  common.HexToAddress(cfg.ArbitrumSocket),
+			"avalanche": common.HexToAddress(cfg.AvalancheSocket),
+			"polygon":   common.HexToAddress(cfg.PolygonSocket),
+			"base":      common.HexToAddress(cfg.BaseSocket),
+		}
+
+		refuel_chaind_ids := map[string]*big.Int{
+			"optimism":  big.NewInt(10),
+			"arbitrum":  big.NewInt(42161),
+			"avalanche": big.NewInt(43114),
+			"polygon":   big.NewInt(137),
+			"base":      big.NewInt(8453),
+		}
+
+		return NewRefuel(deps.Clients, refuel_addresses, refuel_chaind_ids, abi)
+	})
+}
@@ -24,6 +24,7 @@ func ReadAbi(relativePath string) (*abi.ABI, error) {
 		logger.GlobalLogger.Errorf("failed decode abi: %v", err)
 		return nil, err
 	}
+	RegisterABI(&abi)
 
 	return &abi, nil
 }
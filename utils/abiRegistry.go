@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"base/config"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+var knownMethods sync.Map
+
+func init() {
+	RegisterABI(config.Erc20ABI)
+}
+
+func RegisterABI(parsed *abi.ABI) {
+	if parsed == nil {
+		return
+	}
+	for _, method := range parsed.Methods {
+		method := method
+		knownMethods.LoadOrStore(string(method.ID), &method)
+	}
+}
+
+func LookupMethod(data []byte) (*abi.Method, bool) {
+	if len(data) < 4 {
+		return nil, false
+	}
+	method, ok := knownMethods.Load(string(data[:4]))
+	if !ok {
+		return nil, false
+	}
+	return method.(*abi.Method), true
+}
+
+func DecodeCall(data []byte) string {
+	if len(data) == 0 {
+		return "native transfer"
+	}
+
+	method, ok := LookupMethod(data)
+	if !ok {
+		return fmt.Sprintf("unknown method 0x%s", hex.EncodeToString(data[:min(4, len(data))]))
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return fmt.Sprintf("%s (failed to decode arguments: %v)", method.Sig, err)
+	}
+
+	parts := make([]string, 0, len(args))
+	for i, arg := range args {
+		name := method.Inputs[i].Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", name, arg))
+	}
+
+	return fmt.Sprintf("%s(%s)", method.Name, strings.Join(parts, ", "))
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeMnemonic brings the phrase to the NFKD, lowercase, single-spaced form BIP-39 seeds
+// are computed from, and checks its words against the English wordlist and its checksum, so a
+// typo is reported instead of silently deriving other wallets.
+func NormalizeMnemonic(mnemonic string) (string, error) {
+	words := strings.Fields(strings.ToLower(norm.NFKD.String(mnemonic)))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return "", errors.New("мнемоника должна состоять из 12, 15, 18, 21 или 24 слов")
+	}
+
+	normalized := strings.Join(words, " ")
+	if _, err := bip39.MnemonicToByteArray(normalized); err != nil {
+		if errors.Is(err, bip39.ErrChecksumIncorrect) {
+			return "", errors.New("неверная контрольная сумма мнемоники, проверьте порядок и написание слов")
+		}
+		for _, word := range words {
+			if _, ok := bip39.GetWordIndex(word); !ok {
+				return "", fmt.Errorf("слова %q нет в словаре BIP-39", word)
+			}
+		}
+		return "", fmt.Errorf("некорректная мнемоника: %v", err)
+	}
+	return normalized, nil
+}
+
+// DeriveHDKey derives the private key at path from a normalized BIP-39 mnemonic with BIP-32,
+// the same way hardware wallets and MetaMask do.
+func DeriveHDKey(mnemonic, passphrase string, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	seed := bip39.NewSeed(norm.NFKD.String(mnemonic), norm.NFKD.String(passphrase))
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	n := crypto.S256().Params().N
+	for _, index := range path {
+		data := make([]byte, 0, 37)
+		if index >= 0x80000000 {
+			data = append(data, 0)
+			data = append(data, key...)
+		} else {
+			parent, err := crypto.ToECDSA(key)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, crypto.CompressPubkey(&parent.PublicKey)...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		// BIP-32 skips to the next index in these cases, their probability is below 2^-127
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(n) >= 0 {
+			return nil, errors.New("недопустимый ключ на этом пути деривации")
+		}
+		child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+		child.Mod(child, n)
+		if child.Sign() == 0 {
+			return nil, errors.New("недопустимый ключ на этом пути деривации")
+		}
+
+		key, chainCode = math.PaddedBigBytes(child, 32), sum[32:]
+	}
+
+	return crypto.ToECDSA(key)
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveHDKey(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"m/44'/60'/0'/0/0", "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"},
+		{"m/44'/60'/0'/0/1", "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"},
+	}
+	for _, tt := range tests {
+		path, err := accounts.ParseDerivationPath(tt.path)
+		if err != nil {
+			t.Fatalf("ParseDerivationPath(%s): %v", tt.path, err)
+		}
+		key, err := DeriveHDKey(testMnemonic, "", path)
+		if err != nil {
+			t.Fatalf("DeriveHDKey(%s): %v", tt.path, err)
+		}
+		if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != tt.want {
+			t.Errorf("address at %s = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeMnemonic(t *testing.T) {
+	got, err := NormalizeMnemonic("  Abandon abandon\tabandon abandon abandon abandon\nabandon abandon abandon abandon abandon ABOUT ")
+	if err != nil {
+		t.Fatalf("NormalizeMnemonic: %v", err)
+	}
+	if got != testMnemonic {
+		t.Errorf("NormalizeMnemonic = %q, want %q", got, testMnemonic)
+	}
+
+	invalid := map[string]string{
+		"word count":    "abandon abandon abandon",
+		"unknown word":  "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abuot",
+		"bad checksum":  "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"swapped words": "about abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+	}
+	for name, mnemonic := range invalid {
+		if _, err := NormalizeMnemonic(mnemonic); err == nil {
+			t.Errorf("%s: NormalizeMnemonic(%q) succeeded", name, mnemonic)
+		}
+	}
+}
@@ -9,8 +9,8 @@ import (
 
 func IsNativeToken(tokenAddr common.Address) bool {
 	nativeTokens := map[common.Address]bool{
-		config.WETH:     true,
-		config.WooFiETH: true,
+		config.Chains.Base.WETH: true,
+		config.Chains.Base.ETH:  true,
 	}
 
 	return nativeTokens[tokenAddr]
@@ -25,7 +25,3 @@ func IsNativeTokenBySymbol(tokenSymbol string) bool {
 	}
 	return false
 }
-
-func CheckAdaptDecimals(tokenSymbol string) {
-
-}